@@ -5,17 +5,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/app"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/audio"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/notification"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/scheduler"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/service"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/state"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/updater"
 	"github.com/spf13/cobra"
 )
@@ -85,6 +90,156 @@ commands to install and manage as a system service.`,
 		},
 	})
 
+	// Schedule inspection commands
+	nextCmd := &cobra.Command{
+		Use:   "next",
+		Short: "Show upcoming reminders",
+		Run:   runNext,
+	}
+	nextCmd.Flags().IntP("count", "n", 5, "number of reminders to show")
+
+	simulateCmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Show every reminder of a day",
+		Run:   runSimulate,
+	}
+	simulateCmd.Flags().String("date", "", "day to simulate in YYYY-MM-DD format (default: today)")
+
+	scheduleCmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Inspect reminder schedules",
+	}
+	scheduleCmd.AddCommand(&cobra.Command{
+		Use:   "explain [schedule]",
+		Short: "Describe the configured schedule, or the given one, in plain English",
+		Long: `Describe the configured schedule, or the given one, in plain English.
+
+Examples:
+  rest-time-reminder schedule explain
+  rest-time-reminder schedule explain "every 45 minutes between 9am and 6pm on weekdays"
+  rest-time-reminder schedule explain "at :00 and :30 except at lunch"`,
+		Run: runScheduleExplain,
+	})
+
+	rootCmd.AddCommand(nextCmd, simulateCmd, scheduleCmd)
+
+	// One-off timer commands
+	remindCmd := &cobra.Command{
+		Use:   "remind",
+		Short: "Schedule one-off reminders",
+		Long: `Schedule one-off reminders that are delivered by the running instance.
+
+Examples:
+  rest-time-reminder remind in 10m "check the oven"
+  rest-time-reminder remind at 15:30 "stand-up meeting"`,
+	}
+	remindCmd.AddCommand(
+		&cobra.Command{
+			Use:   "in <duration> [message]",
+			Short: "Remind after a duration (e.g., 10m, 1h30m)",
+			Args:  cobra.MinimumNArgs(1),
+			Run:   runRemindAdd,
+		},
+		&cobra.Command{
+			Use:   "at <HH:MM> [message]",
+			Short: "Remind at a time of day",
+			Args:  cobra.MinimumNArgs(1),
+			Run:   runRemindAdd,
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: "List pending one-off reminders",
+			Args:  cobra.NoArgs,
+			Run:   runRemindList,
+		},
+		&cobra.Command{
+			Use:   "cancel <id>",
+			Short: "Cancel a pending one-off reminder",
+			Args:  cobra.ExactArgs(1),
+			Run:   runRemindCancel,
+		},
+	)
+	rootCmd.AddCommand(remindCmd)
+
+	// Focus session commands
+	focusCmd := &cobra.Command{
+		Use:   "focus <duration>",
+		Short: "Pause regular reminders for a focus session (e.g., 50m)",
+		Long: `Start a focus session that pauses regular reminders. When it ends,
+a distinct break reminder fires and reminders stay paused for the
+configured focus break before the normal schedule resumes.`,
+		Args: cobra.ExactArgs(1),
+		Run:  runFocusStart,
+	}
+	focusCmd.AddCommand(
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show the remaining focus time",
+			Args:  cobra.NoArgs,
+			Run:   func(_ *cobra.Command, _ []string) { printFocusStatus(openStore(loadConfig())) },
+		},
+		&cobra.Command{
+			Use:   "stop",
+			Short: "End the focus session early",
+			Args:  cobra.NoArgs,
+			Run:   runFocusStop,
+		},
+	)
+	rootCmd.AddCommand(focusCmd)
+
+	// Break commands
+	breakCmd := &cobra.Command{
+		Use:   "break",
+		Short: "Control the break in progress",
+	}
+	breakCmd.AddCommand(
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show the remaining break time",
+			Args:  cobra.NoArgs,
+			Run:   func(_ *cobra.Command, _ []string) { printBreakStatus(openStore(loadConfig())) },
+		},
+		&cobra.Command{
+			Use:   "stop",
+			Short: "End the break early and stop the ambient sound",
+			Args:  cobra.NoArgs,
+			Run:   runBreakStop,
+		},
+	)
+	rootCmd.AddCommand(breakCmd)
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "ack",
+		Short: "Acknowledge reminders so escalating ones start quiet again",
+		Args:  cobra.NoArgs,
+		Run:   runAck,
+	})
+
+	// Sound commands
+	soundCmd := &cobra.Command{
+		Use:   "sound",
+		Short: "Try out and inspect sounds",
+	}
+	soundTestCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Play the sound a reminder would play",
+		Args:  cobra.NoArgs,
+		Run:   runSoundTest,
+	}
+	soundTestCmd.Flags().String("file", "", "sound file or directory to play instead of the configured sound")
+	soundTestCmd.Flags().Float64("volume", 0, "playback volume (0.0 - 1.0) instead of the configured volume")
+	soundTestCmd.Flags().String("say", "", "read the given text aloud with the speech command instead of playing a sound")
+	soundCmd.AddCommand(
+		soundTestCmd,
+		&cobra.Command{
+			Use:   "info <file>",
+			Short: "Show the format, sample rate, channels, duration and peak level of a sound file",
+			Args:  cobra.ExactArgs(1),
+			Run:   runSoundInfo,
+		},
+	)
+	rootCmd.AddCommand(soundCmd)
+
 	// Service commands
 	rootCmd.AddCommand(
 		&cobra.Command{
@@ -109,8 +264,8 @@ commands to install and manage as a system service.`,
 		},
 		&cobra.Command{
 			Use:   "status",
-			Short: "Show service status",
-			Run:   func(_ *cobra.Command, _ []string) { runServiceCommand("status") },
+			Short: "Show service, focus session and break status",
+			Run:   runStatus,
 		},
 	)
 
@@ -129,20 +284,7 @@ func runApp(_ *cobra.Command, _ []string) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
 	slog.SetDefault(logger)
 
-	// Load configuration
-	cfg, err := config.Load(cfgFile)
-	if err != nil {
-		slog.Error("failed to load configuration", "error", err)
-		os.Exit(1)
-	}
-
-	// Override config with CLI flags
-	if interval != "" {
-		cfg.Reminder.Interval = interval
-	}
-	if sound != "" {
-		cfg.Sound.File = sound
-	}
+	cfg := loadConfig()
 
 	slog.Info("starting RestTimeReminder",
 		"version", version,
@@ -157,9 +299,12 @@ func runApp(_ *cobra.Command, _ []string) {
 	}()
 
 	// Initialize components
-	player := audio.NewPlayer(cfg.Sound)
-	notifier := notification.NewNotifier(cfg.Notification)
-	sched := scheduler.New(cfg.Reminder, player, notifier)
+	sched, player, err := app.Build(cfg)
+	if err != nil {
+		slog.Error("failed to start", "error", err)
+		os.Exit(1)
+	}
+	defer func() { _ = player.Close() }()
 
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -183,6 +328,370 @@ func runApp(_ *cobra.Command, _ []string) {
 	slog.Info("RestTimeReminder stopped gracefully")
 }
 
+// loadConfig loads the configuration and applies CLI flag overrides.
+func loadConfig() *config.Config {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// Override config with CLI flags
+	if interval != "" {
+		cfg.Reminder.Interval = interval
+		cfg.Reminder.Schedule = ""
+	}
+	if sound != "" {
+		cfg.Sound.File = sound
+		cfg.Sound.Files = nil
+	}
+
+	return cfg
+}
+
+// openStore opens the state store shared with the running instance.
+func openStore(cfg *config.Config) *state.Store {
+	store, err := state.New(cfg.State.Dir)
+	if err != nil {
+		slog.Error("failed to open state directory", "error", err)
+		os.Exit(1)
+	}
+	return store
+}
+
+// runRemindAdd schedules a one-off reminder ("in" or "at" depending on the command)
+func runRemindAdd(cmd *cobra.Command, args []string) {
+	store := openStore(loadConfig())
+
+	at, err := state.ParseWhen(cmd.Name(), args[0], time.Now())
+	if err != nil {
+		slog.Error("invalid reminder time", "error", err)
+		os.Exit(1)
+	}
+
+	timer, err := store.AddTimer(at, strings.Join(args[1:], " "))
+	if err != nil {
+		slog.Error("failed to schedule reminder", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Reminder %s scheduled for %s\n", timer.ID, timer.At.Format("Mon 2006-01-02 15:04:05"))
+}
+
+// runRemindList prints pending one-off reminders
+func runRemindList(_ *cobra.Command, _ []string) {
+	store := openStore(loadConfig())
+
+	timers, err := store.Timers()
+	if err != nil {
+		slog.Error("failed to list reminders", "error", err)
+		os.Exit(1)
+	}
+
+	if len(timers) == 0 {
+		fmt.Println("No pending reminders.")
+		return
+	}
+	for _, t := range timers {
+		fmt.Printf("%-4s %s  %q\n", t.ID, t.At.Format("Mon 2006-01-02 15:04:05"), t.Message)
+	}
+}
+
+// runRemindCancel cancels a pending one-off reminder
+func runRemindCancel(_ *cobra.Command, args []string) {
+	store := openStore(loadConfig())
+
+	if err := store.CancelTimer(args[0]); err != nil {
+		slog.Error("failed to cancel reminder", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Reminder %s cancelled\n", args[0])
+}
+
+// runFocusStart starts a focus session
+func runFocusStart(_ *cobra.Command, args []string) {
+	store := openStore(loadConfig())
+
+	d, err := time.ParseDuration(args[0])
+	if err != nil {
+		slog.Error("invalid focus duration", "duration", args[0], "error", err)
+		os.Exit(1)
+	}
+
+	f, err := store.StartFocus(time.Now(), d)
+	if err != nil {
+		slog.Error("failed to start focus session", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Focus session started, reminders paused until %s\n", f.End.Format("15:04:05"))
+}
+
+// runFocusStop ends the focus session early
+func runFocusStop(_ *cobra.Command, _ []string) {
+	store := openStore(loadConfig())
+
+	if err := store.StopFocus(); err != nil {
+		slog.Error("failed to stop focus session", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Focus session stopped")
+}
+
+// printFocusStatus prints the remaining time of the focus session
+func printFocusStatus(store *state.Store) {
+	f, err := store.Focus()
+	if err != nil {
+		slog.Error("failed to read focus session", "error", err)
+		os.Exit(1)
+	}
+
+	if f == nil {
+		fmt.Println("Focus: inactive")
+		return
+	}
+
+	remaining := f.Remaining(time.Now()).Round(time.Second)
+	fmt.Printf("Focus: %s remaining (until %s)\n", remaining, f.End.Format("15:04:05"))
+}
+
+// runSoundTest plays the configured sound, or the given file, like a reminder would
+func runSoundTest(cmd *cobra.Command, _ []string) {
+	file, _ := cmd.Flags().GetString("file")
+	say, _ := cmd.Flags().GetString("say")
+	cfg := loadConfig()
+
+	if cmd.Flags().Changed("volume") {
+		volume, _ := cmd.Flags().GetFloat64("volume")
+		if volume < 0 || volume > 1 {
+			slog.Error("invalid volume, must be between 0.0 and 1.0", "volume", volume)
+			os.Exit(1)
+		}
+		cfg.Sound.Volume = volume
+	}
+	if !cfg.Sound.Enabled {
+		fmt.Println("Sound is disabled in the configuration, playing anyway")
+		cfg.Sound.Enabled = true
+	}
+	if say != "" {
+		cfg.Sound.Speech.Enabled = true
+	}
+
+	notifier := notification.NewNotifier(cfg.Notification)
+	player := audio.NewPlayer(cfg.Sound, audio.WithAlert(func() error {
+		return notifier.Alert(cfg.Notification.Title, cfg.Notification.Message)
+	}))
+	defer func() { _ = player.Close() }()
+	if err := player.Validate(file); err != nil {
+		slog.Warn("some sounds cannot be played and will be skipped", "error", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if say != "" {
+		fmt.Println("Speaking...")
+		if err := player.Speak(ctx, say, 0); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("failed to speak", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println("Playing...")
+	if err := player.Play(ctx, file, 0); err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error("failed to play sound", "error", err)
+		os.Exit(1)
+	}
+}
+
+// runSoundInfo prints the properties of a sound file, or why it cannot be decoded
+func runSoundInfo(_ *cobra.Command, args []string) {
+	info, err := audio.Inspect(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	channels := fmt.Sprint(info.Channels)
+	switch info.Channels {
+	case 1:
+		channels += " (mono)"
+	case 2:
+		channels += " (stereo)"
+	}
+
+	fmt.Printf("File:        %s\n", info.Path)
+	fmt.Printf("Format:      %s\n", info.Format)
+	fmt.Printf("Sample rate: %d Hz\n", info.SampleRate)
+	fmt.Printf("Channels:    %s\n", channels)
+	fmt.Printf("Duration:    %s\n", info.Duration.Round(time.Millisecond))
+	fmt.Printf("Peak:        %.3f (%.1f dBFS)\n", info.Peak, info.PeakDBFS())
+}
+
+// runBreakStop ends the break in progress
+func runBreakStop(_ *cobra.Command, _ []string) {
+	store := openStore(loadConfig())
+
+	if err := store.StopBreak(); err != nil {
+		slog.Error("failed to stop break", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Break stopped")
+}
+
+// runAck acknowledges the reminders so far, resetting the volume escalation
+func runAck(_ *cobra.Command, _ []string) {
+	store := openStore(loadConfig())
+
+	if err := store.Acknowledge(time.Now()); err != nil {
+		slog.Error("failed to acknowledge reminders", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Reminders acknowledged")
+}
+
+// printBreakStatus prints the remaining time of the break in progress
+func printBreakStatus(store *state.Store) {
+	b, err := store.Break()
+	if err != nil {
+		slog.Error("failed to read break", "error", err)
+		os.Exit(1)
+	}
+
+	if b == nil {
+		fmt.Println("Break: none")
+		return
+	}
+
+	remaining := b.Remaining(time.Now()).Round(time.Second)
+	fmt.Printf("Break: %s remaining (until %s)\n", remaining, b.End.Format("15:04:05"))
+}
+
+// runStatus prints the service status followed by the focus session and break status,
+// exiting with an error if the service status cannot be read
+func runStatus(_ *cobra.Command, _ []string) {
+	cfg := loadConfig()
+
+	svc := service.New(cfg)
+	statusErr := svc.Execute("status")
+
+	store := openStore(cfg)
+	printFocusStatus(store)
+	printBreakStatus(store)
+
+	if statusErr != nil {
+		slog.Error("service command failed", "command", "status", "error", statusErr)
+		os.Exit(1)
+	}
+}
+
+// runNext prints the upcoming reminders
+func runNext(cmd *cobra.Command, _ []string) {
+	count, _ := cmd.Flags().GetInt("count")
+	cfg := loadConfig()
+
+	sched := scheduler.New(cfg.Reminder, nil, nil)
+	triggers, err := sched.Next(time.Now(), count)
+	if err != nil {
+		slog.Error("failed to compute upcoming reminders", "error", err)
+		os.Exit(1)
+	}
+
+	printTriggers(cfg, triggers)
+}
+
+// runSimulate prints every reminder of a single day
+func runSimulate(cmd *cobra.Command, _ []string) {
+	date, _ := cmd.Flags().GetString("date")
+	cfg := loadConfig()
+
+	day := time.Now()
+	if date != "" {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", date, time.Local); err != nil {
+			slog.Error("invalid date", "date", date, "error", err)
+			os.Exit(1)
+		}
+	}
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+
+	sched := scheduler.New(cfg.Reminder, nil, nil)
+	triggers, err := sched.Simulate(from, from.AddDate(0, 0, 1))
+	if err != nil {
+		slog.Error("failed to simulate schedule", "error", err)
+		os.Exit(1)
+	}
+
+	printTriggers(cfg, triggers)
+}
+
+// runScheduleExplain renders a schedule rule back to English
+func runScheduleExplain(_ *cobra.Command, args []string) {
+	if len(args) > 0 {
+		input := strings.Join(args, " ")
+		rule, err := scheduler.ParseSchedule(input)
+		if err != nil {
+			printScheduleError(err)
+			os.Exit(1)
+		}
+		fmt.Printf("Schedule: %s\n", rule)
+		return
+	}
+
+	cfg := loadConfig()
+	rule, err := scheduler.New(cfg.Reminder, nil, nil).Rule()
+	if err != nil {
+		printScheduleError(err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Schedule: %s\n", rule)
+	for _, v := range cfg.Reminder.Variants {
+		fmt.Printf("  %s from %s to %s: %q\n", v.Name, v.From, v.To, v.Message)
+	}
+}
+
+// printScheduleError prints a schedule error, underlining the offending word
+func printScheduleError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+	var perr *scheduler.ParseError
+	if errors.As(err, &perr) && perr.Word != "" {
+		fmt.Fprintf(os.Stderr, "  %s\n  %s%s\n", perr.Input, strings.Repeat(" ", perr.Offset), strings.Repeat("^", len(perr.Word)))
+	}
+}
+
+// printTriggers prints reminders with the message and sound they will use
+func printTriggers(cfg *config.Config, triggers []scheduler.Trigger) {
+	if len(triggers) == 0 {
+		fmt.Println("No reminders scheduled.")
+		return
+	}
+
+	for _, tr := range triggers {
+		variant, message := tr.Variant, tr.Message
+		if variant == "" {
+			variant = "default"
+		}
+		if message == "" {
+			message = cfg.Notification.Message
+		}
+		sound := audio.Describe(cfg.Sound, tr.Sound)
+
+		volume := ""
+		if tr.Volume > 0 {
+			volume = fmt.Sprintf(", volume: %.2g", tr.Volume)
+		}
+
+		fmt.Printf("%s  %-12s %q (sound: %s%s)\n", tr.Time.Format("Mon 2006-01-02 15:04"), variant, message, sound, volume)
+	}
+}
+
 // runServiceCommand handles service management commands
 func runServiceCommand(command string) {
 	cfg, err := config.Load(cfgFile)
@@ -0,0 +1,57 @@
+// Package app wires the configured components into a reminder scheduler,
+// for both the foreground command and the system service.
+package app
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/audio"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/notification"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/scheduler"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/state"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/tips"
+)
+
+// Build creates the scheduler of cfg along with its player, which the
+// caller must close once the scheduler stopped. Misconfigured notification
+// settings and tips are logged and left out; a failing sound preflight is
+// returned.
+func Build(cfg *config.Config) (*scheduler.Scheduler, *audio.Player, error) {
+	store, err := state.New(cfg.State.Dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open state directory: %w", err)
+	}
+
+	notifier := notification.NewNotifier(cfg.Notification)
+	if err := notifier.Validate(); err != nil {
+		slog.Warn("some notification settings are misconfigured and will be ignored", "error", err)
+	}
+	player := audio.NewPlayer(cfg.Sound, audio.WithAlert(func() error {
+		return notifier.Alert(cfg.Notification.Title, cfg.Notification.Message)
+	}))
+	if err := player.Preflight(audio.Sources(cfg)...); err != nil {
+		_ = player.Close()
+		return nil, nil, fmt.Errorf("sound preflight failed, fix the sounds above or set sound.preflight to warn: %w", err)
+	}
+
+	opts := []scheduler.Option{
+		scheduler.WithTimers(store),
+		scheduler.WithFocus(store, cfg.Focus),
+		scheduler.WithAmbience(store, player),
+		scheduler.WithAcks(store),
+		scheduler.WithSpeech(player),
+		scheduler.WithNotification(cfg.Notification, cfg.Templates),
+	}
+	if cfg.Notification.Tips.Enabled {
+		pool, err := tips.New(cfg.Notification.Tips)
+		if err != nil {
+			slog.Warn("some tips cannot be used", "error", err)
+		}
+		opts = append(opts, scheduler.WithTips(pool))
+	}
+	sched := scheduler.New(cfg.Reminder, player, notifier, opts...)
+	notifier.HandleActions(sched)
+	return sched, player, nil
+}
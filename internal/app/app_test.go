@@ -0,0 +1,32 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/audio"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+func TestBuild(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	cfg.State.Dir = t.TempDir()
+	cfg.Sound.Backend = "null"
+	sched, player, err := Build(cfg)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	defer func() { _ = player.Close() }()
+	if sched == nil {
+		t.Fatal("Build() returned no scheduler")
+	}
+
+	// A sound that cannot be played stops the reminder from starting
+	cfg.Sound.Enabled = true
+	cfg.Sound.File = "missing.mp3"
+	cfg.Sound.Preflight = audio.PreflightFail
+	if _, _, err := Build(cfg); err == nil {
+		t.Error("expected the sound preflight to fail")
+	}
+}
@@ -0,0 +1,221 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// NoisePrefix marks ambient sounds that are generated noise rather than
+// files, e.g. "noise:pink".
+const NoisePrefix = "noise:"
+
+// Noise colors.
+const (
+	noiseWhite = "white"
+	noisePink  = "pink"
+	noiseBrown = "brown"
+)
+
+// noisePeak is the amplitude of generated noise at full volume, leaving
+// headroom like tones do.
+const noisePeak = 0.5
+
+// noise generates endless mono noise. White noise has equal energy at every
+// frequency, pink noise loses 3 dB per octave and brown noise 6 dB per
+// octave, which makes them sound progressively deeper and softer.
+type noise struct {
+	color string
+	rand  *rand.Rand
+	// pink holds the filter state of pink noise
+	pink [7]float64
+	// brown is the last sample of brown noise
+	brown float64
+}
+
+// newNoise creates a noise generator of the given color.
+func newNoise(color string) (*noise, error) {
+	switch color {
+	case noiseWhite, noisePink, noiseBrown:
+	default:
+		return nil, fmt.Errorf("invalid noise %q: must be white, pink or brown", color)
+	}
+	return &noise{
+		color: color,
+		rand:  rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}, nil
+}
+
+// Stream implements beep.Streamer.
+func (n *noise) Stream(samples [][2]float64) (int, bool) {
+	for i := range samples {
+		v := noisePeak * n.next()
+		samples[i] = [2]float64{v, v}
+	}
+	return len(samples), true
+}
+
+// Err implements beep.Streamer.
+func (n *noise) Err() error {
+	return nil
+}
+
+// next returns the next sample in [-1, 1].
+func (n *noise) next() float64 {
+	white := 2*n.rand.Float64() - 1
+	switch n.color {
+	case noisePink:
+		// Paul Kellet's filter approximates the -3 dB/octave slope with
+		// a handful of one-pole filters
+		b := &n.pink
+		b[0] = 0.99886*b[0] + white*0.0555179
+		b[1] = 0.99332*b[1] + white*0.0750759
+		b[2] = 0.96900*b[2] + white*0.1538520
+		b[3] = 0.86650*b[3] + white*0.3104856
+		b[4] = 0.55000*b[4] + white*0.5329522
+		b[5] = -0.7616*b[5] - white*0.0168980
+		pink := b[0] + b[1] + b[2] + b[3] + b[4] + b[5] + b[6] + white*0.5362
+		b[6] = white * 0.115926
+		return clamp(pink * 0.11)
+	case noiseBrown:
+		// Integrating white noise gives -6 dB/octave; the leak keeps it
+		// from drifting away from zero
+		n.brown = (n.brown + 0.02*white) / 1.02
+		return clamp(n.brown * 3.5)
+	}
+	return white
+}
+
+// clamp limits v to [-1, 1].
+func clamp(v float64) float64 {
+	return max(-1, min(1, v))
+}
+
+// ambientFades parses the fade durations of the ambient soundtrack.
+func ambientFades(cfg config.AmbientConfig) (fadeIn, fadeOut time.Duration, err error) {
+	fadeIn, fadeOut, err = fades(config.SoundConfig{FadeIn: cfg.FadeIn, FadeOut: cfg.FadeOut})
+	if err != nil {
+		return 0, 0, fmt.Errorf("ambient: %w", err)
+	}
+	return fadeIn, fadeOut, nil
+}
+
+// PlayAmbient plays the ambient soundtrack for d, looping sound files and
+// fading out at the end. It plays alongside reminder sounds and blocks
+// until d has passed, StopAmbient is called or ctx is done, in which case
+// it returns ctx.Err(). It does nothing if no soundtrack is configured.
+func (p *Player) PlayAmbient(ctx context.Context, d time.Duration) error {
+	if !p.config.Enabled || p.config.Ambient.Sound == "" || d <= 0 {
+		return nil
+	}
+
+	source, rate, err := p.ambientSource()
+	if err != nil {
+		return err
+	}
+
+	fadeIn, fadeOut, err := ambientFades(p.config.Ambient)
+	if err != nil {
+		slog.Warn("ignoring ambient sound fades", "error", err)
+	}
+	total := rate.N(d)
+	shaped := newEnvelope(beep.Take(total, source), p.volume(p.config.Ambient.Volume), total, rate.N(fadeIn), rate.N(fadeOut))
+
+	// Give up on outputs that take much longer than the break
+	playCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	playCtx, stop := context.WithTimeoutCause(playCtx, d+maxDurationGrace, errMaxDuration)
+	defer stop()
+
+	p.setAmbientCancel(cancel)
+	defer p.setAmbientCancel(nil)
+
+	slog.Info("🎧 ambient sound started", "sound", p.config.Ambient.Sound, "duration", d)
+	err = p.output.Play(playCtx, shaped)
+	switch cause := context.Cause(playCtx); {
+	case err == nil:
+		slog.Debug("ambient sound finished")
+	case errors.Is(err, ErrDeviceLost):
+		p.mu.Lock()
+		p.ready = false
+		p.mu.Unlock()
+		return fmt.Errorf("failed to play ambient sound: %w", err)
+	case errors.Is(cause, errStopped):
+		slog.Info("ambient sound stopped")
+	case errors.Is(cause, errMaxDuration):
+		slog.Warn("ambient sound took too long, stopped")
+	case ctx.Err() != nil:
+		return ctx.Err()
+	default:
+		return fmt.Errorf("failed to play ambient sound: %w", err)
+	}
+	return nil
+}
+
+// ambientSource prepares the output and returns the endless ambient stream
+// and the output rate it is played at.
+func (p *Player) ambientSource() (beep.Streamer, beep.SampleRate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.outputErr != nil {
+		return nil, 0, p.outputErr
+	}
+
+	if color, ok := strings.CutPrefix(p.config.Ambient.Sound, NoisePrefix); ok {
+		n, err := newNoise(color)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Noise sounds the same at any rate, so only pick one if the
+		// output has not been initialized yet
+		rate := p.rate
+		if !p.ready {
+			rate = toneRate
+		}
+		if err := p.ensureOutput(rate); err != nil {
+			return nil, 0, err
+		}
+		return n, p.rate, nil
+	}
+
+	sound := p.nextSound(p.playlistFor(p.config.Ambient.Sound))
+	if sound == nil {
+		return nil, 0, fmt.Errorf("ambient sound %q cannot be played", p.config.Ambient.Sound)
+	}
+	format := sound.Format()
+	if err := p.ensureOutput(format.SampleRate); err != nil {
+		return nil, 0, err
+	}
+	looped := beep.Loop(-1, sound.Streamer(0, sound.Len()))
+	return resample(looped, format.SampleRate, p.rate), p.rate, nil
+}
+
+// setAmbientCancel records how to interrupt the ambient sound.
+func (p *Player) setAmbientCancel(cancel context.CancelCauseFunc) {
+	p.cancelMu.Lock()
+	defer p.cancelMu.Unlock()
+	p.ambientCancel = cancel
+}
+
+// HasAmbient reports whether an ambient soundtrack is configured.
+func (p *Player) HasAmbient() bool {
+	return p.config.Enabled && p.config.Ambient.Sound != ""
+}
+
+// StopAmbient ends the ambient sound, if any.
+func (p *Player) StopAmbient() {
+	p.cancelMu.Lock()
+	defer p.cancelMu.Unlock()
+
+	if p.ambientCancel != nil {
+		p.ambientCancel(errStopped)
+	}
+}
@@ -0,0 +1,171 @@
+package audio
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// roughness is the RMS of the difference between neighboring samples
+// relative to the RMS of the samples. It is high for noise dominated by
+// high frequencies and low for deep noise.
+func roughness(samples []float64) float64 {
+	var sum, diff float64
+	for i, v := range samples {
+		sum += v * v
+		if i > 0 {
+			d := v - samples[i-1]
+			diff += d * d
+		}
+	}
+	return math.Sqrt(diff / sum)
+}
+
+func TestNoise(t *testing.T) {
+	colors := []string{noiseWhite, noisePink, noiseBrown}
+	rough := make(map[string]float64)
+
+	for _, color := range colors {
+		t.Run(color, func(t *testing.T) {
+			n, err := newNoise(color)
+			if err != nil {
+				t.Fatalf("newNoise() error = %v", err)
+			}
+
+			buf := make([][2]float64, toneRate)
+			if count, ok := n.Stream(buf); count != len(buf) || !ok {
+				t.Fatalf("expected endless noise, got %d samples (ok %v)", count, ok)
+			}
+			left := make([]float64, len(buf))
+			for i, v := range buf {
+				if v[0] != v[1] {
+					t.Fatalf("expected mono noise, got %v at %d", v, i)
+				}
+				left[i] = v[0]
+			}
+			if p := peak(left); p > noisePeak || p < noisePeak/10 {
+				t.Errorf("expected peak within (%.2f, %.2f], got %.3f", noisePeak/10, noisePeak, p)
+			}
+			rough[color] = roughness(left)
+		})
+	}
+
+	if rough[noiseWhite] <= rough[noisePink] || rough[noisePink] <= rough[noiseBrown] {
+		t.Errorf("expected white noise to be brighter than pink and pink than brown, got %v", rough)
+	}
+
+	if _, err := newNoise("purple"); err == nil {
+		t.Error("expected error for unknown noise color")
+	}
+}
+
+func TestPlayer_PlayAmbient(t *testing.T) {
+	t.Run("Noise fades out", func(t *testing.T) {
+		out := &captureOutput{}
+		player := NewPlayer(config.SoundConfig{
+			Enabled: true,
+			Ambient: config.AmbientConfig{Sound: "noise:pink", Volume: 1, FadeOut: "100ms"},
+		}, WithOutput(out))
+		defer func() { _ = player.Close() }()
+
+		if err := player.PlayAmbient(context.Background(), 500*time.Millisecond); err != nil {
+			t.Fatalf("PlayAmbient() error = %v", err)
+		}
+		if len(out.samples) != toneRate.N(500*time.Millisecond) {
+			t.Fatalf("expected 500ms at %d Hz, got %d samples", toneRate, len(out.samples))
+		}
+		end := len(out.samples)
+		if body, tail := peak(out.samples[:end/2]), peak(out.samples[end-100:]); tail >= body/10 {
+			t.Errorf("expected the end to fade out, peak %.3f vs %.3f", tail, body)
+		}
+	})
+
+	t.Run("Loops files", func(t *testing.T) {
+		out := &captureOutput{}
+		player := NewPlayer(config.SoundConfig{
+			Enabled: true,
+			Ambient: config.AmbientConfig{Sound: "testdata/tone.flac", Volume: 1},
+		}, WithOutput(out))
+		defer func() { _ = player.Close() }()
+
+		// The file is 100ms long at 22.05kHz
+		if err := player.PlayAmbient(context.Background(), 350*time.Millisecond); err != nil {
+			t.Fatalf("PlayAmbient() error = %v", err)
+		}
+		if out.rate != 22050 || len(out.samples) != 7717 {
+			t.Fatalf("expected 350ms at 22050 Hz, got %d samples at %d Hz", len(out.samples), out.rate)
+		}
+		if p := peak(out.samples[3*2205:]); p < 0.2 {
+			t.Errorf("expected the file to play again, got peak %.3f in the fourth loop", p)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		out := &captureOutput{}
+		player := NewPlayer(config.SoundConfig{Enabled: true}, WithOutput(out))
+		defer func() { _ = player.Close() }()
+
+		if err := player.PlayAmbient(context.Background(), time.Second); err != nil || out.plays != 0 {
+			t.Errorf("expected nothing to play, got %d plays (err %v)", out.plays, err)
+		}
+	})
+}
+
+func TestPlayer_StopAmbient(t *testing.T) {
+	out := &blockingOutput{started: make(chan struct{})}
+	player := NewPlayer(config.SoundConfig{
+		Enabled: true,
+		Ambient: config.AmbientConfig{Sound: "noise:brown", Volume: 1},
+	}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	result := make(chan error, 1)
+	go func() { result <- player.PlayAmbient(context.Background(), time.Minute) }()
+	select {
+	case <-out.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ambient sound did not start")
+	}
+
+	// Stopping the reminder sound leaves the ambient sound alone
+	player.Stop()
+	select {
+	case err := <-result:
+		t.Fatalf("ambient sound ended by Stop: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	player.StopAmbient()
+	if err := waitPlay(t, result, time.Second); err != nil {
+		t.Errorf("expected StopAmbient to end playback cleanly, got %v", err)
+	}
+}
+
+func TestPlayer_Validate_Ambient(t *testing.T) {
+	tests := []struct {
+		name    string
+		ambient config.AmbientConfig
+		wantErr bool
+	}{
+		{name: "Off", ambient: config.AmbientConfig{}},
+		{name: "Noise", ambient: config.AmbientConfig{Sound: "noise:white"}},
+		{name: "File", ambient: config.AmbientConfig{Sound: "testdata/tone.flac"}},
+		{name: "Unknown noise", ambient: config.AmbientConfig{Sound: "noise:purple"}, wantErr: true},
+		{name: "Missing file", ambient: config.AmbientConfig{Sound: "testdata/missing.wav"}, wantErr: true},
+		{name: "Invalid fade", ambient: config.AmbientConfig{Sound: "noise:pink", FadeOut: "slowly"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := NewPlayer(config.SoundConfig{Enabled: true, Backend: "null", Ambient: tt.ambient})
+			defer func() { _ = player.Close() }()
+
+			if err := player.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
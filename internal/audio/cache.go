@@ -0,0 +1,173 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+
+	"github.com/faiface/beep"
+	"github.com/fsnotify/fsnotify"
+)
+
+// errUnchanged is wrapped around the error of a sound file that failed to
+// decode and has not changed since.
+var errUnchanged = errors.New("unchanged since it last failed")
+
+// cache keeps decoded sounds in memory so playback never waits on the disk.
+// Files that fail to decode are remembered too, so they are not decoded
+// again on every use. Entries are dropped when their file changes and
+// decoded again on next use.
+type cache struct {
+	mu     sync.Mutex
+	sounds map[string]*beep.Buffer
+	// failed holds why files failed to decode
+	failed  map[string]error
+	watcher *fsnotify.Watcher
+	// watched holds the directories being watched
+	watched map[string]bool
+}
+
+// newCache creates an empty cache. The file watcher starts with the first entry.
+func newCache() *cache {
+	return &cache{
+		sounds:  make(map[string]*beep.Buffer),
+		failed:  make(map[string]error),
+		watched: make(map[string]bool),
+	}
+}
+
+// get returns the decoded sound for path, decoding it on a cache miss.
+func (c *cache) get(path string, decode func(string) (beep.StreamSeekCloser, beep.Format, error)) (*beep.Buffer, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if buf, ok := c.sounds[absPath]; ok {
+		return buf, nil
+	}
+	if err, ok := c.failed[absPath]; ok {
+		return nil, fmt.Errorf("%w (%w)", err, errUnchanged)
+	}
+
+	// Watch before decoding so a change during decoding is not missed
+	dir := filepath.Dir(absPath)
+	c.watch(dir)
+
+	buf, err := decodeBuffer(absPath, decode)
+	if err != nil {
+		// Without a watcher the file is tried again next time, as a fix
+		// would go unnoticed
+		if c.watched[dir] {
+			c.failed[absPath] = err
+		}
+		return nil, err
+	}
+
+	c.sounds[absPath] = buf
+	return buf, nil
+}
+
+// decodeBuffer decodes the whole file at path into memory.
+func decodeBuffer(path string, decode func(string) (beep.StreamSeekCloser, beep.Format, error)) (*beep.Buffer, error) {
+	streamer, format, err := decode(path)
+	if err != nil {
+		return nil, err
+	}
+	return buffer(streamer, format)
+}
+
+// buffer decodes the whole stream into memory and closes it.
+func buffer(streamer beep.StreamSeekCloser, format beep.Format) (*beep.Buffer, error) {
+	defer func() { _ = streamer.Close() }()
+
+	buf := beep.NewBuffer(format)
+	buf.Append(streamer)
+	// Some decoders report the end of the stream as io.EOF
+	if err := streamer.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode: %w", err)
+	}
+	return buf, nil
+}
+
+// watch starts watching dir for changes. The directory rather than the file
+// is watched because editors often replace files instead of writing them.
+// Without a watcher the cache still works, it just never invalidates.
+func (c *cache) watch(dir string) {
+	if c.watched[dir] {
+		return
+	}
+
+	if c.watcher == nil {
+		w, err := fsnotify.NewWatcher()
+		if err != nil {
+			slog.Warn("failed to watch sound files, changes need a restart", "error", err)
+			return
+		}
+		c.watcher = w
+		go c.run(w)
+	}
+
+	if err := c.watcher.Add(dir); err != nil {
+		slog.Warn("failed to watch sound directory, changes need a restart", "dir", dir, "error", err)
+		return
+	}
+	c.watched[dir] = true
+}
+
+// run drops cache entries whose files change until the watcher is closed.
+func (c *cache) run(w *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			c.invalidate(filepath.Clean(event.Name))
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("sound file watcher error", "error", err)
+		}
+	}
+}
+
+// invalidate drops the cached sound or failure for path, if any.
+func (c *cache) invalidate(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, decoded := c.sounds[path]
+	_, failed := c.failed[path]
+	if decoded || failed {
+		delete(c.sounds, path)
+		delete(c.failed, path)
+		slog.Debug("sound file changed, reloading on next use", "path", path)
+	}
+}
+
+// Close stops watching for changes.
+func (c *cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.watcher == nil {
+		return nil
+	}
+	err := c.watcher.Close()
+	c.watcher = nil
+	c.watched = make(map[string]bool)
+	// Failures are only forgotten when a watched file changes
+	clear(c.failed)
+	return err
+}
@@ -0,0 +1,172 @@
+package audio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// copyFixture copies a file from testdata into dir under the given name.
+func copyFixture(t testing.TB, fixture, dir, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	path := copyFixture(t, "tone.flac", t.TempDir(), "sound")
+	player := NewPlayer(config.SoundConfig{})
+	defer func() { _ = player.Close() }()
+
+	first, err := player.sounds.get(path, player.loadFromFile)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if first.Format().SampleRate != 22050 {
+		t.Fatalf("expected the FLAC fixture, got %d Hz", first.Format().SampleRate)
+	}
+
+	// Replace the file the way editors do: write a new file, then swap it in
+	copyFixture(t, "silence.ogg", filepath.Dir(path), "sound.tmp")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		player.sounds.mu.Lock()
+		_, cached := player.sounds.sounds[path]
+		player.sounds.mu.Unlock()
+		if !cached {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected removing the file to invalidate the cache")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		t.Fatal(err)
+	}
+	second, err := player.sounds.get(path, player.loadFromFile)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if second.Format().SampleRate != 48000 {
+		t.Errorf("expected the replaced OGG file, got %d Hz", second.Format().SampleRate)
+	}
+	if again, _ := player.sounds.get(path, player.loadFromFile); again != second {
+		t.Error("expected a cache hit for the unchanged file")
+	}
+}
+
+func TestCache_Failed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sound.flac")
+	if err := os.WriteFile(path, []byte("fLaC not really"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	player := NewPlayer(config.SoundConfig{})
+	defer func() { _ = player.Close() }()
+
+	decodes := 0
+	decode := func(path string) (beep.StreamSeekCloser, beep.Format, error) {
+		decodes++
+		return player.loadFromFile(path)
+	}
+
+	// A broken file is decoded once, then reported as unchanged
+	if _, err := player.sounds.get(path, decode); err == nil || errors.Is(err, errUnchanged) {
+		t.Fatalf("expected a decoding error, got %v", err)
+	}
+	if _, err := player.sounds.get(path, decode); !errors.Is(err, errUnchanged) {
+		t.Fatalf("expected the remembered error, got %v", err)
+	}
+	if decodes != 1 {
+		t.Errorf("expected 1 decode, got %d", decodes)
+	}
+
+	// Fixing the file makes it load again
+	copyFixture(t, "tone.flac", filepath.Dir(path), "sound.flac")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := player.sounds.get(path, decode); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the fixed file to load")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPlayer_Validate_Caches(t *testing.T) {
+	dir := t.TempDir()
+	path := copyFixture(t, "silence.mp3", dir, "silence.mp3")
+	player := NewPlayer(config.SoundConfig{Enabled: true})
+	defer func() { _ = player.Close() }()
+
+	if err := player.Validate(path, filepath.Join(dir, "missing.ogg")); err == nil {
+		t.Error("expected an error for the missing file")
+	}
+
+	// Cached sounds must play even if the disk becomes unavailable
+	decodes := 0
+	_, err := player.sounds.get(path, func(string) (beep.StreamSeekCloser, beep.Format, error) {
+		decodes++
+		return player.loadFromFile(path)
+	})
+	if err != nil || decodes != 0 {
+		t.Errorf("expected a cache hit after Validate, got %d decodes (error %v)", decodes, err)
+	}
+}
+
+// drain streams a whole sound, as the speaker does.
+func drain(s beep.Streamer) {
+	buf := make([][2]float64, 512)
+	for {
+		if _, ok := s.Stream(buf); !ok {
+			return
+		}
+	}
+}
+
+func BenchmarkSound(b *testing.B) {
+	path := copyFixture(b, "silence.mp3", b.TempDir(), "silence.mp3")
+	player := NewPlayer(config.SoundConfig{})
+	defer func() { _ = player.Close() }()
+
+	b.Run("decode", func(b *testing.B) {
+		for b.Loop() {
+			s, _, err := player.loadFromFile(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			drain(s)
+			_ = s.Close()
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		for b.Loop() {
+			sound, err := player.sounds.get(path, player.loadFromFile)
+			if err != nil {
+				b.Fatal(err)
+			}
+			drain(sound.Streamer(0, sound.Len()))
+		}
+	})
+}
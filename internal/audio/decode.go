@@ -0,0 +1,99 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/flac"
+	"github.com/faiface/beep/mp3"
+	"github.com/faiface/beep/vorbis"
+	"github.com/faiface/beep/wav"
+)
+
+// Format identifies an audio container format.
+type Format string
+
+// Supported audio formats.
+const (
+	FormatWAV    Format = "wav"
+	FormatMP3    Format = "mp3"
+	FormatVorbis Format = "ogg"
+	FormatFLAC   Format = "flac"
+)
+
+// ErrUnsupportedFormat is returned for files that are not WAV, MP3, OGG Vorbis or FLAC.
+var ErrUnsupportedFormat = errors.New("unsupported audio format")
+
+// headerSize is the number of bytes needed to recognize every format.
+const headerSize = 12
+
+// DetectFormat identifies the format of an audio file from its first bytes,
+// falling back to the file extension when the content is not recognized.
+func DetectFormat(name string, header []byte) (Format, error) {
+	switch {
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return FormatWAV, nil
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return FormatVorbis, nil
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return FormatFLAC, nil
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return FormatMP3, nil
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		// MPEG audio frame sync
+		return FormatMP3, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".wav", ".wave":
+		return FormatWAV, nil
+	case ".mp3":
+		return FormatMP3, nil
+	case ".ogg", ".oga":
+		return FormatVorbis, nil
+	case ".flac":
+		return FormatFLAC, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, filepath.Base(name))
+}
+
+// Decode detects the format of r and decodes it. The name is only used as
+// a hint when the content is not recognized. On success the returned
+// streamer owns r and closes it.
+func Decode(name string, r io.ReadSeekCloser) (beep.StreamSeekCloser, beep.Format, Format, error) {
+	header := make([]byte, headerSize)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, beep.Format{}, "", fmt.Errorf("failed to read header: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, beep.Format{}, "", fmt.Errorf("failed to rewind: %w", err)
+	}
+
+	kind, err := DetectFormat(name, header[:n])
+	if err != nil {
+		return nil, beep.Format{}, "", err
+	}
+
+	var streamer beep.StreamSeekCloser
+	var format beep.Format
+	switch kind {
+	case FormatWAV:
+		streamer, format, err = wav.Decode(r)
+	case FormatMP3:
+		streamer, format, err = mp3.Decode(r)
+	case FormatVorbis:
+		streamer, format, err = vorbis.Decode(r)
+	case FormatFLAC:
+		streamer, format, err = flac.Decode(r)
+	}
+	if err != nil {
+		return nil, beep.Format{}, kind, fmt.Errorf("failed to decode %s: %w", strings.ToUpper(string(kind)), err)
+	}
+	return streamer, format, kind, nil
+}
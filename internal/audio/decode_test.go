@@ -0,0 +1,130 @@
+package audio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+func TestDecode_Fixtures(t *testing.T) {
+	tests := []struct {
+		file       string
+		kind       Format
+		sampleRate beep.SampleRate
+		channels   int
+		duration   time.Duration
+	}{
+		{file: "testdata/silence.mp3", kind: FormatMP3, sampleRate: 44100, channels: 2},
+		{file: "testdata/silence.ogg", kind: FormatVorbis, sampleRate: 48000, channels: 2, duration: 12800 * time.Second / 48000},
+		{file: "testdata/tone.flac", kind: FormatFLAC, sampleRate: 22050, channels: 1, duration: 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			streamer, format, kind, err := Decode(tt.file, f)
+			if err != nil {
+				_ = f.Close()
+				t.Fatalf("Decode() error = %v", err)
+			}
+			defer func() { _ = streamer.Close() }()
+
+			if kind != tt.kind {
+				t.Errorf("expected format %q, got %q", tt.kind, kind)
+			}
+			if format.SampleRate != tt.sampleRate {
+				t.Errorf("expected sample rate %d, got %d", tt.sampleRate, format.SampleRate)
+			}
+			if format.NumChannels != tt.channels {
+				t.Errorf("expected %d channels, got %d", tt.channels, format.NumChannels)
+			}
+			if streamer.Len() <= 0 {
+				t.Errorf("expected samples, got length %d", streamer.Len())
+			}
+			if tt.duration > 0 {
+				if got := format.SampleRate.D(streamer.Len()); got != tt.duration {
+					t.Errorf("expected duration %v, got %v", tt.duration, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		header  []byte
+		want    Format
+		wantErr bool
+	}{
+		{name: "Content wins over extension", file: "bell.mp3", header: []byte("RIFF\x00\x00\x00\x00WAVEfmt "), want: FormatWAV},
+		{name: "ID3 tag", file: "sound", header: []byte("ID3\x04\x00"), want: FormatMP3},
+		{name: "MPEG frame sync", file: "sound", header: []byte{0xFF, 0xFB, 0x90, 0xC0}, want: FormatMP3},
+		{name: "Ogg page", file: "sound.mp3", header: []byte("OggS\x00\x02"), want: FormatVorbis},
+		{name: "FLAC marker", file: "sound.wav", header: []byte("fLaC\x00"), want: FormatFLAC},
+		{name: "Extension fallback", file: "Chime.FLAC", header: []byte("????"), want: FormatFLAC},
+		{name: "Unknown", file: "notes.txt", header: []byte("hello"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectFormat(tt.file, tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DetectFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayer_Validate(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "broken.flac")
+	if err := os.WriteFile(corrupt, []byte("fLaC not really"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	unknown := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(unknown, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.SoundConfig
+		wantErr bool
+		// errIs is the error wanted in the chain, if any
+		errIs error
+	}{
+		{name: "Disabled", cfg: config.SoundConfig{File: corrupt}},
+		{name: "Embedded default", cfg: config.SoundConfig{Enabled: true, File: "bell.wav"}},
+		{name: "MP3", cfg: config.SoundConfig{Enabled: true, File: "testdata/silence.mp3"}},
+		{name: "OGG", cfg: config.SoundConfig{Enabled: true, File: "testdata/silence.ogg"}},
+		{name: "FLAC", cfg: config.SoundConfig{Enabled: true, File: "testdata/tone.flac"}},
+		{name: "Missing", cfg: config.SoundConfig{Enabled: true, File: filepath.Join(dir, "missing.mp3")}, wantErr: true, errIs: os.ErrNotExist},
+		{name: "Corrupt", cfg: config.SoundConfig{Enabled: true, File: corrupt}, wantErr: true},
+		{name: "Unsupported", cfg: config.SoundConfig{Enabled: true, File: unknown}, wantErr: true, errIs: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewPlayer(tt.cfg).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("expected %v, got %v", tt.errIs, err)
+			}
+		})
+	}
+}
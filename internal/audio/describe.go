@@ -0,0 +1,62 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// describeBell describes the embedded sound.
+const describeBell = "built-in bell"
+
+// Describe returns what plays for a requested sound file or directory, or
+// for the configured sounds if file is empty, e.g. "tone C5:200ms",
+// "routine breathing" or "a.wav, sounds/ (shuffle)".
+func Describe(cfg config.SoundConfig, file string) string {
+	if !cfg.Enabled {
+		return "off"
+	}
+
+	var names []string
+	playlist := false
+	for _, path := range soundPaths(cfg, file) {
+		if path == "" {
+			continue
+		}
+		name, dir := describePath(path)
+		names = append(names, name)
+		playlist = playlist || dir
+	}
+
+	switch {
+	case len(names) == 0:
+		return describeBell
+	case len(names) == 1 && !playlist:
+		return names[0]
+	}
+	order := cfg.Order
+	if order == "" {
+		order = orderSequential
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(names, ", "), order)
+}
+
+// describePath describes one sound path, reporting whether it is a
+// directory of sounds.
+func describePath(path string) (name string, dir bool) {
+	if name, ok := strings.CutPrefix(path, RoutinePrefix); ok {
+		return "routine " + name, false
+	}
+	if pattern, ok := strings.CutPrefix(path, TonePrefix); ok {
+		return "tone " + pattern, false
+	}
+	if path == "bell.wav" {
+		return describeBell, false
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return strings.TrimSuffix(path, string(os.PathSeparator)) + string(os.PathSeparator), true
+	}
+	return path, false
+}
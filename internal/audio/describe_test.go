@@ -0,0 +1,46 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+func TestDescribe(t *testing.T) {
+	dir := t.TempDir()
+	sounds := filepath.Join(dir, "sounds")
+	if err := os.Mkdir(sounds, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.SoundConfig
+		file string
+		want string
+	}{
+		{name: "Disabled", cfg: config.SoundConfig{File: "chime.mp3"}, want: "off"},
+		{name: "Default", cfg: config.SoundConfig{Enabled: true}, want: "built-in bell"},
+		{name: "Bell", cfg: config.SoundConfig{Enabled: true, File: "bell.wav"}, want: "built-in bell"},
+		{name: "File", cfg: config.SoundConfig{Enabled: true, File: "chime.mp3"}, want: "chime.mp3"},
+		{name: "Tone", cfg: config.SoundConfig{Enabled: true, File: "chime.mp3", Tone: "C5:200ms"}, want: "tone C5:200ms"},
+		{
+			name: "Playlist",
+			cfg:  config.SoundConfig{Enabled: true, File: "chime.mp3", Files: []string{sounds}, Order: "shuffle"},
+			want: "chime.mp3, " + sounds + string(os.PathSeparator) + " (shuffle)",
+		},
+		{name: "Directory", cfg: config.SoundConfig{Enabled: true, Files: []string{sounds}}, want: sounds + string(os.PathSeparator) + " (sequential)"},
+		{name: "Routine", cfg: config.SoundConfig{Enabled: true, File: "chime.mp3"}, file: "routine:breathing", want: "routine breathing"},
+		{name: "Requested tone", cfg: config.SoundConfig{Enabled: true, Tone: "C5:200ms"}, file: "tone:A4:1s", want: "tone A4:1s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Describe(tt.cfg, tt.file); got != tt.want {
+				t.Errorf("Describe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
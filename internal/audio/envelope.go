@@ -0,0 +1,104 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// dBPerHalving is the attenuation that makes a sound seem half as loud.
+const dBPerHalving = 10
+
+// Decibels converts a volume setting to a gain in decibels. Perceived
+// loudness halves about every 10 dB, so each halving of the volume removes
+// 10 dB: 1.0 is 0 dB, 0.5 is -10 dB and 0.25 is -20 dB. Volumes at or below
+// zero are silent and volumes above 1.0 are treated as 1.0.
+func Decibels(volume float64) float64 {
+	switch {
+	case volume <= 0:
+		return math.Inf(-1)
+	case volume >= 1:
+		return 0
+	}
+	return dBPerHalving * math.Log2(volume)
+}
+
+// amplitude converts a volume setting to the factor samples are scaled by.
+func amplitude(volume float64) float64 {
+	return math.Pow(10, Decibels(volume)/20)
+}
+
+// fades parses the fade durations of a sound configuration.
+func fades(cfg config.SoundConfig) (fadeIn, fadeOut time.Duration, err error) {
+	if cfg.FadeIn != "" {
+		if fadeIn, err = time.ParseDuration(cfg.FadeIn); err != nil || fadeIn < 0 {
+			return 0, 0, fmt.Errorf("invalid fade_in %q", cfg.FadeIn)
+		}
+	}
+	if cfg.FadeOut != "" {
+		if fadeOut, err = time.ParseDuration(cfg.FadeOut); err != nil || fadeOut < 0 {
+			return 0, 0, fmt.Errorf("invalid fade_out %q", cfg.FadeOut)
+		}
+	}
+	return fadeIn, fadeOut, nil
+}
+
+// envelope applies the volume and fades to a stream of known length. Fades
+// ramp the volume setting rather than the amplitude so they sound even.
+type envelope struct {
+	s      beep.Streamer
+	volume float64
+	// gain is the amplitude outside of fades
+	gain float64
+	// pos and total are the current position and length in samples
+	pos, total int
+	// fadeIn and fadeOut are fade lengths in samples
+	fadeIn, fadeOut int
+}
+
+// newEnvelope shapes s, which is total samples long.
+func newEnvelope(s beep.Streamer, volume float64, total, fadeIn, fadeOut int) *envelope {
+	return &envelope{
+		s:       s,
+		volume:  volume,
+		gain:    amplitude(volume),
+		total:   total,
+		fadeIn:  fadeIn,
+		fadeOut: fadeOut,
+	}
+}
+
+// Stream implements beep.Streamer.
+func (e *envelope) Stream(samples [][2]float64) (int, bool) {
+	n, ok := e.s.Stream(samples)
+	for i := range samples[:n] {
+		g := e.gain
+		if r := e.ramp(e.pos); r < 1 {
+			g = amplitude(e.volume * r)
+		}
+		samples[i][0] *= g
+		samples[i][1] *= g
+		e.pos++
+	}
+	return n, ok
+}
+
+// Err implements beep.Streamer.
+func (e *envelope) Err() error {
+	return e.s.Err()
+}
+
+// ramp returns the fade factor (0 to 1) at sample pos.
+func (e *envelope) ramp(pos int) float64 {
+	r := 1.0
+	if pos < e.fadeIn {
+		r = float64(pos) / float64(e.fadeIn)
+	}
+	if left := e.total - pos - 1; e.fadeOut > 0 && left < e.fadeOut {
+		r = min(r, float64(max(left, 0))/float64(e.fadeOut))
+	}
+	return r
+}
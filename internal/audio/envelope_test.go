@@ -0,0 +1,144 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// render plays n full-scale samples through an envelope and returns the left channel.
+func render(volume float64, n, fadeIn, fadeOut int) []float64 {
+	ones := beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		for i := range samples {
+			samples[i] = [2]float64{1, 1}
+		}
+		return len(samples), true
+	})
+	e := newEnvelope(beep.Take(n, ones), volume, n, fadeIn, fadeOut)
+
+	out := make([]float64, 0, n)
+	buf := make([][2]float64, 64)
+	for {
+		m, ok := e.Stream(buf)
+		for _, s := range buf[:m] {
+			out = append(out, s[0])
+		}
+		if !ok {
+			return out
+		}
+	}
+}
+
+func TestEnvelope_Volume(t *testing.T) {
+	tests := []struct {
+		volume float64
+		dB     float64
+	}{
+		{volume: 1, dB: 0},
+		{volume: 1.5, dB: 0},
+		{volume: 0.5, dB: -10},
+		{volume: 0.25, dB: -20},
+		{volume: 0.125, dB: -30},
+	}
+
+	for _, tt := range tests {
+		samples := render(tt.volume, 100, 0, 0)
+		for i, s := range samples {
+			if got := 20 * math.Log10(s); math.Abs(got-tt.dB) > 1e-9 {
+				t.Fatalf("volume %v: sample %d is %.2f dB, want %.2f dB", tt.volume, i, got, tt.dB)
+			}
+		}
+	}
+
+	for _, s := range render(0, 100, 0, 0) {
+		if s != 0 {
+			t.Fatalf("volume 0: expected silence, got %v", s)
+		}
+	}
+}
+
+func TestEnvelope_Fades(t *testing.T) {
+	const n, fadeIn, fadeOut = 1000, 100, 200
+	samples := render(0.5, n, fadeIn, fadeOut)
+	if len(samples) != n {
+		t.Fatalf("expected %d samples, got %d", n, len(samples))
+	}
+
+	full := amplitude(0.5)
+	if samples[0] != 0 {
+		t.Errorf("expected fade in to start silent, got %v", samples[0])
+	}
+	if samples[n-1] != 0 {
+		t.Errorf("expected fade out to end silent, got %v", samples[n-1])
+	}
+	// Halfway through the fade in the volume setting is halved, i.e. -10 dB more
+	if want := amplitude(0.25); math.Abs(samples[fadeIn/2]-want) > 1e-9 {
+		t.Errorf("expected %v halfway through fade in, got %v", want, samples[fadeIn/2])
+	}
+	for i := 1; i < fadeIn; i++ {
+		if samples[i] <= samples[i-1] {
+			t.Fatalf("fade in not rising at sample %d", i)
+		}
+	}
+	for i := fadeIn; i < n-fadeOut; i++ {
+		if samples[i] != full {
+			t.Fatalf("expected full volume %v at sample %d, got %v", full, i, samples[i])
+		}
+	}
+	for i := n - fadeOut; i < n; i++ {
+		if samples[i] >= samples[i-1] {
+			t.Fatalf("fade out not falling at sample %d", i)
+		}
+	}
+}
+
+func TestFades(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.SoundConfig
+		wantErr bool
+	}{
+		{name: "None", cfg: config.SoundConfig{}},
+		{name: "Both", cfg: config.SoundConfig{FadeIn: "200ms", FadeOut: "1s"}},
+		{name: "Invalid fade in", cfg: config.SoundConfig{FadeIn: "soon"}, wantErr: true},
+		{name: "Negative fade out", cfg: config.SoundConfig{FadeOut: "-1s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := fades(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fades() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlayer_volume(t *testing.T) {
+	tests := []struct {
+		name      string
+		volume    float64
+		maxVolume float64
+		requested float64
+		want      float64
+	}{
+		{name: "Configured", volume: 0.8, maxVolume: 1, want: 0.8},
+		{name: "Requested", volume: 0.8, maxVolume: 1, requested: 0.3, want: 0.3},
+		{name: "Configured capped", volume: 0.8, maxVolume: 0.5, want: 0.5},
+		{name: "Requested capped", volume: 0.4, maxVolume: 0.5, requested: 1, want: 0.5},
+		{name: "No cap", volume: 1, requested: 0.9, want: 0.9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlayer(config.SoundConfig{Volume: tt.volume, MaxVolume: tt.maxVolume})
+			defer func() { _ = p.Close() }()
+
+			if got := p.volume(tt.requested); got != tt.want {
+				t.Errorf("volume(%v) = %v, want %v", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
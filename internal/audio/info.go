@@ -0,0 +1,71 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// Info describes a decoded sound file.
+type Info struct {
+	Path       string
+	Format     Format
+	SampleRate beep.SampleRate
+	Channels   int
+	Duration   time.Duration
+	// Peak is the largest absolute sample value (0.0 - 1.0)
+	Peak float64
+}
+
+// PeakDBFS returns the peak level in decibels relative to full scale.
+func (i Info) PeakDBFS() float64 {
+	return 20 * math.Log10(i.Peak)
+}
+
+// Inspect decodes a sound file completely and reports its properties.
+func Inspect(path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	streamer, format, kind, err := Decode(path, f)
+	if err != nil {
+		_ = f.Close()
+		return Info{}, err
+	}
+	defer func() { _ = streamer.Close() }()
+
+	info := Info{
+		Path:       path,
+		Format:     kind,
+		SampleRate: format.SampleRate,
+		Channels:   format.NumChannels,
+	}
+
+	// Count the samples rather than trusting Len, which is an estimate for
+	// some formats
+	var count int
+	buf := make([][2]float64, 512)
+	for {
+		n, ok := streamer.Stream(buf)
+		for _, sample := range buf[:n] {
+			info.Peak = max(info.Peak, math.Abs(sample[0]), math.Abs(sample[1]))
+		}
+		count += n
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return Info{}, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	info.Duration = format.SampleRate.D(count)
+	return info, nil
+}
@@ -0,0 +1,77 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		file       string
+		kind       Format
+		sampleRate beep.SampleRate
+		channels   int
+		duration   time.Duration
+		peak       float64
+	}{
+		{file: "testdata/tone.flac", kind: FormatFLAC, sampleRate: 22050, channels: 1, duration: 100 * time.Millisecond, peak: 8000.0 / 32768},
+		{file: "testdata/silence.ogg", kind: FormatVorbis, sampleRate: 48000, channels: 2, duration: 12800 * time.Second / 48000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			info, err := Inspect(tt.file)
+			if err != nil {
+				t.Fatalf("Inspect() error = %v", err)
+			}
+			if info.Format != tt.kind {
+				t.Errorf("expected format %q, got %q", tt.kind, info.Format)
+			}
+			if info.SampleRate != tt.sampleRate {
+				t.Errorf("expected sample rate %d, got %d", tt.sampleRate, info.SampleRate)
+			}
+			if info.Channels != tt.channels {
+				t.Errorf("expected %d channels, got %d", tt.channels, info.Channels)
+			}
+			if info.Duration != tt.duration {
+				t.Errorf("expected duration %v, got %v", tt.duration, info.Duration)
+			}
+			if math.Abs(info.Peak-tt.peak) > 0.01 {
+				t.Errorf("expected peak %.3f, got %.3f", tt.peak, info.Peak)
+			}
+		})
+	}
+}
+
+func TestInspect_Errors(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(text, []byte("not a sound"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Inspect(text); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+	if _, err := Inspect(filepath.Join(dir, "missing.wav")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestInfo_PeakDBFS(t *testing.T) {
+	if got := (Info{Peak: 1}).PeakDBFS(); got != 0 {
+		t.Errorf("expected 0 dBFS at full scale, got %v", got)
+	}
+	if got := (Info{Peak: 0.5}).PeakDBFS(); math.Abs(got+6.02) > 0.01 {
+		t.Errorf("expected -6.02 dBFS at half scale, got %v", got)
+	}
+	if got := (Info{}).PeakDBFS(); !math.IsInf(got, -1) {
+		t.Errorf("expected -Inf for silence, got %v", got)
+	}
+}
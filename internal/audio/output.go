@@ -0,0 +1,214 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/wav"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// Output is where the player sends decoded, resampled and volume-adjusted
+// audio, such as the sound card or a file.
+type Output interface {
+	// Init prepares the output for streams at the given sample rate. It is
+	// called once before the first Play.
+	Init(rate beep.SampleRate) error
+	// Play plays the stream and blocks until it has finished or ctx is
+	// done, in which case it stops playback and returns ctx.Err().
+	Play(ctx context.Context, s beep.Streamer) error
+}
+
+// newOutput creates the output selected by the sound.backend setting.
+func newOutput(cfg config.SoundConfig) (Output, error) {
+	switch cfg.Backend {
+	case "", "speaker":
+		return &speakerOutput{}, nil
+	case "null":
+		return &nullOutput{}, nil
+	case "wav":
+		if cfg.OutputFile == "" {
+			return nil, fmt.Errorf("the wav sound backend needs an output_file")
+		}
+		return &wavOutput{path: cfg.OutputFile}, nil
+	}
+	return nil, fmt.Errorf("invalid sound backend %q: must be speaker, null or wav", cfg.Backend)
+}
+
+// stallTimeout is how long the speaker may stop pulling samples before the
+// device is considered lost.
+const stallTimeout = 2 * time.Second
+
+// maxLead is how far ahead of real time the speaker may pull samples. A
+// device that accepts audio faster than it can play it is not playing it.
+const maxLead = time.Second
+
+// speakerOutput plays through the system audio device.
+type speakerOutput struct {
+	rate beep.SampleRate
+}
+
+// Init initializes the speaker with a buffer of 1/10th of a second. It may
+// be called again to reopen the device.
+func (o *speakerOutput) Init(rate beep.SampleRate) error {
+	if err := speaker.Init(rate, rate.N(time.Second/10)); err != nil {
+		return fmt.Errorf("failed to initialize speaker: %w", err)
+	}
+	o.rate = rate
+	return nil
+}
+
+// Play plays s on the speaker. It returns ErrDeviceLost if the device
+// stops consuming audio at the pace it is played.
+func (o *speakerOutput) Play(ctx context.Context, s beep.Streamer) error {
+	start := time.Now()
+	pc := newPacer(s, start)
+	done := make(chan struct{})
+	speaker.Play(beep.Seq(pc, beep.Callback(func() {
+		close(done)
+	})))
+
+	ticker := time.NewTicker(stallTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			if err := pc.check(o.rate, start, time.Now()); err != nil {
+				return err
+			}
+			return s.Err()
+		case <-ctx.Done():
+			// Only end this stream, other sounds may be playing alongside
+			pc.stop()
+			return ctx.Err()
+		case now := <-ticker.C:
+			if err := pc.check(o.rate, start, now); err != nil {
+				speaker.Clear()
+				return err
+			}
+		}
+	}
+}
+
+// pacer tracks how fast the speaker consumes a stream, and ends it early
+// when stopped.
+type pacer struct {
+	s       beep.Streamer
+	mu      sync.Mutex
+	pulled  int
+	last    time.Time
+	stopped bool
+}
+
+// newPacer wraps s, starting the clock at start.
+func newPacer(s beep.Streamer, start time.Time) *pacer {
+	return &pacer{s: s, last: start}
+}
+
+// Stream implements beep.Streamer.
+func (p *pacer) Stream(samples [][2]float64) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return 0, false
+	}
+	n, ok := p.s.Stream(samples)
+	p.pulled += n
+	p.last = time.Now()
+	return n, ok
+}
+
+// stop ends the stream the next time the speaker pulls from it.
+func (p *pacer) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopped = true
+}
+
+// Err implements beep.Streamer.
+func (p *pacer) Err() error {
+	return p.s.Err()
+}
+
+// check returns ErrDeviceLost if the stream stopped being consumed or was
+// consumed faster than real time since start.
+func (p *pacer) check(rate beep.SampleRate, start, now time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if now.Sub(p.last) > stallTimeout {
+		return fmt.Errorf("%w: playback stalled for %s", ErrDeviceLost, now.Sub(p.last).Round(time.Second))
+	}
+	if p.pulled > rate.N(now.Sub(start)+maxLead) {
+		return fmt.Errorf("%w: playback ran ahead of real time", ErrDeviceLost)
+	}
+	return nil
+}
+
+// nullOutput discards audio, for machines without a sound device.
+type nullOutput struct{}
+
+// Init does nothing.
+func (o *nullOutput) Init(beep.SampleRate) error {
+	return nil
+}
+
+// Play consumes s without waiting.
+func (o *nullOutput) Play(ctx context.Context, s beep.Streamer) error {
+	buf := make([][2]float64, 512)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, ok := s.Stream(buf); !ok {
+			return s.Err()
+		}
+	}
+}
+
+// wavOutput renders each sound to a WAV file, replacing the previous one.
+type wavOutput struct {
+	path string
+	mu   sync.Mutex
+	rate beep.SampleRate
+}
+
+// Init records the sample rate of the file.
+func (o *wavOutput) Init(rate beep.SampleRate) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.rate = rate
+	return nil
+}
+
+// Play writes s to the output file as 16-bit stereo.
+func (o *wavOutput) Play(ctx context.Context, s beep.Streamer) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f, err := os.Create(o.path)
+	if err != nil {
+		return fmt.Errorf("failed to create sound output file: %w", err)
+	}
+	format := beep.Format{SampleRate: o.rate, NumChannels: 2, Precision: 2}
+	if err := wav.Encode(f, s, format); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write sound output file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write sound output file: %w", err)
+	}
+	return s.Err()
+}
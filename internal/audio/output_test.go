@@ -0,0 +1,210 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// captureOutput records everything played on it.
+type captureOutput struct {
+	rate    beep.SampleRate
+	inits   int
+	plays   int
+	samples []float64
+}
+
+func (o *captureOutput) Init(rate beep.SampleRate) error {
+	o.rate = rate
+	o.inits++
+	return nil
+}
+
+func (o *captureOutput) Play(_ context.Context, s beep.Streamer) error {
+	o.plays++
+	o.samples = o.samples[:0]
+	buf := make([][2]float64, 512)
+	for {
+		n, ok := s.Stream(buf)
+		for _, v := range buf[:n] {
+			o.samples = append(o.samples, v[0])
+		}
+		if !ok {
+			return s.Err()
+		}
+	}
+}
+
+func TestPlayer_Play_Output(t *testing.T) {
+	out := &captureOutput{}
+	player := NewPlayer(config.SoundConfig{
+		Enabled:  true,
+		Tone:     "A4:100ms",
+		Envelope: "flat",
+		Volume:   0.5,
+	}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	// The first sound sets the output rate
+	if err := player.Play(context.Background(), "", 0); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if out.rate != toneRate || len(out.samples) != 4410 {
+		t.Fatalf("expected 4410 samples at %d Hz, got %d at %d Hz", toneRate, len(out.samples), out.rate)
+	}
+	if got, want := peak(out.samples), tonePeak*amplitude(0.5); math.Abs(got-want) > 0.01 {
+		t.Errorf("expected peak %.3f at volume 0.5, got %.3f", want, got)
+	}
+
+	// A 22.05kHz file is decoded, resampled and attenuated
+	if err := player.Play(context.Background(), "testdata/tone.flac", 0); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if out.inits != 1 || out.plays != 2 {
+		t.Errorf("expected 1 init and 2 plays, got %d and %d", out.inits, out.plays)
+	}
+	if len(out.samples) < 4408 || len(out.samples) > 4412 {
+		t.Errorf("expected about 4410 samples after resampling, got %d", len(out.samples))
+	}
+	if got, want := peak(out.samples), 8000.0/32768*amplitude(0.5); math.Abs(got-want) > 0.01 {
+		t.Errorf("expected peak %.3f, got %.3f", want, got)
+	}
+}
+
+func TestWavOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	player := NewPlayer(config.SoundConfig{Enabled: true, Backend: "wav", OutputFile: path, Volume: 1})
+	defer func() { _ = player.Close() }()
+
+	if err := player.Play(context.Background(), "testdata/tone.flac", 0); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamer, format, err := wav.Decode(f)
+	if err != nil {
+		t.Fatalf("failed to decode output: %v", err)
+	}
+	defer func() { _ = streamer.Close() }()
+
+	if format.SampleRate != 22050 || format.NumChannels != 2 {
+		t.Errorf("expected 22050 Hz stereo, got %d Hz with %d channels", format.SampleRate, format.NumChannels)
+	}
+	if streamer.Len() != 2205 {
+		t.Errorf("expected 2205 samples, got %d", streamer.Len())
+	}
+}
+
+func TestNewOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.SoundConfig
+		wantErr bool
+	}{
+		{name: "Default", cfg: config.SoundConfig{}},
+		{name: "Speaker", cfg: config.SoundConfig{Backend: "speaker"}},
+		{name: "Null", cfg: config.SoundConfig{Backend: "null"}},
+		{name: "WAV", cfg: config.SoundConfig{Backend: "wav", OutputFile: "out.wav"}},
+		{name: "WAV without file", cfg: config.SoundConfig{Backend: "wav"}, wantErr: true},
+		{name: "Unknown", cfg: config.SoundConfig{Backend: "pulse"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := NewPlayer(tt.cfg)
+			defer func() { _ = player.Close() }()
+
+			if err := player.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNullOutput_Play(t *testing.T) {
+	player := NewPlayer(config.SoundConfig{Enabled: true, Backend: "null", Tone: "C5:50ms"})
+	defer func() { _ = player.Close() }()
+
+	if err := player.Play(context.Background(), "", 0); err != nil {
+		t.Errorf("Play() error = %v", err)
+	}
+}
+
+func TestPacer_check(t *testing.T) {
+	const rate beep.SampleRate = 1000
+	start := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		pulled  int
+		last    time.Duration
+		now     time.Duration
+		wantErr bool
+	}{
+		{name: "Real time", pulled: 3100, last: 3 * time.Second, now: 3 * time.Second},
+		{name: "Just started", pulled: 200, last: 0, now: 0},
+		{name: "Stalled", pulled: 1100, last: time.Second, now: 4 * time.Second, wantErr: true},
+		{name: "Ahead of real time", pulled: 5000, last: 2 * time.Second, now: 2 * time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPacer(nil, start)
+			p.pulled = tt.pulled
+			p.last = start.Add(tt.last)
+
+			err := p.check(rate, start, start.Add(tt.now))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrDeviceLost) {
+				t.Errorf("expected ErrDeviceLost, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPacer_stop(t *testing.T) {
+	n, _ := newNoise(noiseWhite)
+	p := newPacer(n, time.Now())
+	buf := make([][2]float64, 64)
+
+	if count, ok := p.Stream(buf); count != len(buf) || !ok {
+		t.Fatalf("expected the stream to play, got %d samples (ok %v)", count, ok)
+	}
+	p.stop()
+	if count, ok := p.Stream(buf); count != 0 || ok {
+		t.Errorf("expected a stopped stream to end, got %d samples (ok %v)", count, ok)
+	}
+}
+
+func TestPlayer_Play_MaxVolume(t *testing.T) {
+	out := &captureOutput{}
+	player := NewPlayer(config.SoundConfig{
+		Enabled:   true,
+		File:      "testdata/tone.flac",
+		Volume:    1,
+		MaxVolume: 0.25,
+	}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	// A louder volume asked for by a reminder is still capped
+	if err := player.Play(context.Background(), "", 1); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	want := 8000.0 / 32768 * amplitude(0.25)
+	if got := peak(out.samples); math.Abs(got-want) > 0.01 {
+		t.Errorf("expected peak %.3f at the capped volume, got %.3f", want, got)
+	}
+}
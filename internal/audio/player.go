@@ -3,18 +3,20 @@ package audio
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/faiface/beep"
-	"github.com/faiface/beep/effects"
-	"github.com/faiface/beep/speaker"
 	"github.com/faiface/beep/wav"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
 )
@@ -22,23 +24,106 @@ import (
 //go:embed bell.wav
 var defaultSound []byte
 
+// maxDurationGrace is how much longer than the maximum duration an output
+// may take, e.g. to drain its buffers, before playback is abandoned.
+const maxDurationGrace = time.Second
+
+// Causes for ending playback early.
+var (
+	errStopped     = errors.New("playback stopped")
+	errMaxDuration = errors.New("playback exceeded max duration")
+)
+
+// resampleQuality is the interpolation quality used when converting sample
+// rates; 4 is beep's recommended balance of quality and CPU use.
+const resampleQuality = 4
+
 // Player handles audio playback for reminder notifications.
 type Player struct {
-	config   config.SoundConfig
-	initOnce sync.Once
-	initErr  error
-	mu       sync.Mutex
+	config config.SoundConfig
+	// output is where sounds are played; outputErr is set if the
+	// configured backend is invalid
+	output    Output
+	outputErr error
+	// ready reports whether the output is initialized; retryAt and backoff
+	// throttle initialization attempts while it keeps failing
+	ready   bool
+	retryAt time.Time
+	backoff time.Duration
+	now     func() time.Time
+	// rate is the sample rate the output was initialized with
+	rate beep.SampleRate
+	mu   sync.Mutex
+
+	// cancel interrupts the sound being played and ambientCancel the
+	// ambient sound
+	cancelMu      sync.Mutex
+	cancel        context.CancelCauseFunc
+	ambientCancel context.CancelCauseFunc
+
+	// bell and alert implement the fallbacks used while audio is unavailable
+	bell  io.Writer
+	alert func() error
+
+	// sounds holds decoded sound files
+	sounds *cache
+	// tones holds rendered tone patterns
+	tones map[string]*beep.Buffer
+	// playlists rotate through sound files, keyed by the requested file
+	// (empty for the configured sounds)
+	playlists map[string]*playlist
+	rand      *rand.Rand
+	// embedded is the decoded default sound
+	embeddedOnce sync.Once
+	embedded     *beep.Buffer
+	embeddedErr  error
+}
+
+// Option configures optional Player behavior.
+type Option func(*Player)
+
+// WithOutput plays sounds on out instead of the backend set in the
+// configuration.
+func WithOutput(out Output) Option {
+	return func(p *Player) {
+		p.output = out
+	}
+}
+
+// WithAlert sets how the "notification" fallback alerts the user while
+// audio is unavailable.
+func WithAlert(alert func() error) Option {
+	return func(p *Player) {
+		p.alert = alert
+	}
 }
 
 // NewPlayer creates a new Player instance.
-func NewPlayer(cfg config.SoundConfig) *Player {
-	return &Player{
-		config: cfg,
+func NewPlayer(cfg config.SoundConfig, opts ...Option) *Player {
+	p := &Player{
+		config:    cfg,
+		sounds:    newCache(),
+		tones:     make(map[string]*beep.Buffer),
+		playlists: make(map[string]*playlist),
+		rand:      rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		now:       time.Now,
+		bell:      os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
+	if p.output == nil {
+		p.output, p.outputErr = newOutput(cfg)
+	}
+	return p
 }
 
-// Play plays the configured sound file.
-func (p *Player) Play() error {
+// Play plays the given sound file, or the configured sound file if empty,
+// at the given volume, or the configured volume if zero. The volume is
+// limited to the configured maximum. It blocks until the sound has
+// finished, was cut at the maximum duration or was interrupted by Stop,
+// and returns ctx.Err() if ctx ends first.
+func (p *Player) Play(ctx context.Context, file string, volume float64) error {
 	if !p.config.Enabled {
 		slog.Debug("sound is disabled, skipping playback")
 		return nil
@@ -48,86 +133,244 @@ func (p *Player) Play() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	var streamer beep.StreamSeekCloser
-	var format beep.Format
-	var err error
-
-	// 1. Try to load from custom file if configured
-	// 2. Fallback to embedded sound if file is "bell.wav" or empty
-	if p.config.File != "" && p.config.File != "bell.wav" {
-		streamer, format, err = p.loadFromFile(p.config.File)
+	// 1. Use the next sound of the file, directory or configured playlist
+	// 2. Fallback to embedded sound if it is "bell.wav" or nothing can be loaded
+	sound := p.nextSound(p.playlistFor(file))
+	if sound == nil {
+		embedded, err := p.defaultSound()
 		if err != nil {
-			slog.Warn("failed to load custom sound, falling back to default", "path", p.config.File, "error", err)
-			streamer = nil // Reset to ensure fallback
+			return fmt.Errorf("failed to load default sound: %w", err)
 		}
+		sound = embedded
 	}
 
-	// Fallback to embedded sound
-	if streamer == nil {
-		streamer, format, err = p.loadEmbedded()
-		if err != nil {
-			return fmt.Errorf("failed to load default sound: %w", err)
-		}
+	return p.playClip(ctx, sound, volume)
+}
+
+// playClip plays a loaded sound through the output with the volume, fades
+// and maximum duration applied. The caller must hold p.mu.
+func (p *Player) playClip(ctx context.Context, sound clip, volume float64) error {
+	streamer, format := sound.Streamer(0, sound.Len()), sound.Format()
+
+	if p.outputErr != nil {
+		return p.outputErr
 	}
-	defer func() { _ = streamer.Close() }()
 
-	// Initialize speaker if not already done (thread-safe)
-	p.initOnce.Do(func() {
-		// Use a buffer size of 1/10th of a second
-		if err := speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10)); err != nil {
-			p.initErr = fmt.Errorf("failed to initialize speaker: %w", err)
-		}
-	})
+	// Initialize output if not already done, or again after a failure
+	if err := p.ensureOutput(format.SampleRate); err != nil {
+		return p.fallback(err)
+	}
+
+	// The output keeps the rate of the first sound played, so later sounds
+	// at other rates must be converted to avoid playing at the wrong pitch
+	resampled := resample(streamer, format.SampleRate, p.rate)
+
+	// Apply volume and fades
+	fadeIn, fadeOut, err := fades(p.config)
+	if err != nil {
+		slog.Warn("ignoring sound fades", "error", err)
+	}
+	total := p.rate.N(format.SampleRate.D(sound.Len()))
+
+	// Cut long sounds at the maximum duration, fading out before the cut,
+	// and give up on outputs that take much longer than that
+	playCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	limit, err := maxDuration(p.config)
+	if err != nil {
+		slog.Warn("ignoring sound max duration", "error", err)
+	}
+	if r, ok := sound.(*routine); ok {
+		// Routines take as long as their definition says
+		limit = r.Duration()
+	}
+	if limit > 0 {
+		total = min(total, p.rate.N(limit))
+		var stop context.CancelFunc
+		playCtx, stop = context.WithTimeoutCause(playCtx, limit+maxDurationGrace, errMaxDuration)
+		defer stop()
+	}
+	shaped := newEnvelope(beep.Take(total, resampled), p.volume(volume), total, p.rate.N(fadeIn), p.rate.N(fadeOut))
 
-	if p.initErr != nil {
-		return p.initErr
-	}
-
-	// Apply Volume control
-	// Beep volume is logarithmic: 0 is silent, 1 is 100%, but it uses values like -1.0, -2.0...
-	// We map 0.0-1.0 to Beep volume (where 1.0 is original, < 1.0 is quieter)
-	// Base 2: volume of -1.0 is 50%, -2.0 is 25%, etc.
-	// We use the formula: Volume = log2(p.config.Volume)
-	vol := 0.0
-	if p.config.Volume > 0 && p.config.Volume < 1.0 {
-		// Example: Volume 0.5 -> log2(0.5) = -1.0 (50% volume)
-		// Volume 0.25 -> log2(0.25) = -2.0 (25% volume)
-		// We can simplify or use math.Log2, but for simplicity let's use a basic mapping:
-		// We'll use p.config.Volume directly for a linear-ish feel if we set Base properly,
-		// or just use 0.0 for 100% and negative values for lower.
-		// A common way in beep is volume - 1.0 if using Base 2 roughly.
-		// Let's use a simpler mapping: Volume 1.0 -> 0, Volume 0.5 -> -1, etc.
-		// If volume is 0.5, we want it to be -1.0
-		// But let's just use the built-in Gain if available or Volume effect.
-		vol = p.config.Volume - 1.0 // Simple mapping: 1.0 -> 0 (normal), 0.5 -> -0.5 (quieter)
-	}
-
-	volumeControl := &effects.Volume{
-		Streamer: streamer,
-		Base:     2,
-		Volume:   vol,
-		Silent:   p.config.Volume <= 0,
-	}
-
-	// Play the sound
-	done := make(chan bool)
-	speaker.Play(beep.Seq(volumeControl, beep.Callback(func() {
-		done <- true
-	})))
-
-	// Wait for playback to complete (or context cancel if implemented)
-	<-done
-	slog.Debug("sound playback completed")
+	// Play the sound, allowing Stop to interrupt it
+	p.setCancel(cancel)
+	defer p.setCancel(nil)
+
+	err = p.output.Play(playCtx, shaped)
+	switch cause := context.Cause(playCtx); {
+	case err == nil:
+		slog.Debug("sound playback completed")
+	case errors.Is(err, ErrDeviceLost):
+		// Start over with a fresh device for the next sound
+		p.ready = false
+		return p.fallback(fmt.Errorf("%w: %w", ErrUnavailable, err))
+	case errors.Is(cause, errStopped):
+		slog.Debug("sound playback stopped")
+	case errors.Is(cause, errMaxDuration):
+		slog.Warn("sound playback took too long, stopped", "max_duration", limit)
+	case ctx.Err() != nil:
+		return ctx.Err()
+	default:
+		return fmt.Errorf("failed to play sound: %w", err)
+	}
 
 	return nil
 }
 
-// Stop stops any currently playing sound.
+// volume returns the requested volume, or the configured one if zero,
+// limited to the maximum volume.
+func (p *Player) volume(requested float64) float64 {
+	volume := p.config.Volume
+	if requested > 0 {
+		volume = requested
+	}
+	if p.config.MaxVolume > 0 {
+		volume = min(volume, p.config.MaxVolume)
+	}
+	return volume
+}
+
+// validateMaxVolume checks the sound.max_volume setting.
+func validateMaxVolume(limit float64) error {
+	if limit < 0 {
+		return fmt.Errorf("invalid max_volume %v: must not be negative", limit)
+	}
+	return nil
+}
+
+// setCancel records how to interrupt the sound being played.
+func (p *Player) setCancel(cancel context.CancelCauseFunc) {
+	p.cancelMu.Lock()
+	defer p.cancelMu.Unlock()
+	p.cancel = cancel
+}
+
+// maxDuration parses the maximum playback duration; zero means no limit.
+func maxDuration(cfg config.SoundConfig) (time.Duration, error) {
+	if cfg.MaxDuration == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(cfg.MaxDuration)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid max_duration %q", cfg.MaxDuration)
+	}
+	return d, nil
+}
+
+// playlistFor returns the playlist for a requested sound file or directory,
+// or for the configured sounds if file is empty.
+func (p *Player) playlistFor(file string) *playlist {
+	pl, ok := p.playlists[file]
+	if !ok {
+		pl = newPlaylist(p.config.Order, soundPaths(p.config, file), p.rand)
+		p.playlists[file] = pl
+	}
+	return pl
+}
+
+// soundPaths returns the sounds played for a requested sound file or
+// directory, or the configured ones if file is empty.
+func soundPaths(cfg config.SoundConfig, file string) []string {
+	switch {
+	case file != "":
+		return []string{file}
+	case cfg.Tone != "":
+		return []string{TonePrefix + cfg.Tone}
+	}
+	return append([]string{cfg.File}, cfg.Files...)
+}
+
+// nextSound returns the next sound of a playlist, moving on past files
+// that cannot be loaded. It returns nil for the embedded sound.
+func (p *Player) nextSound(pl *playlist) clip {
+	files := pl.files()
+	if len(files) == 0 {
+		return nil
+	}
+
+	skip := make(map[int]bool)
+	for i := pl.next(skip); i >= 0; i = pl.next(skip) {
+		file := files[i]
+		if file == "bell.wav" {
+			return nil
+		}
+		sound, err := p.load(file)
+		if err == nil {
+			return sound
+		}
+		// A file that still fails as before was already reported
+		if errors.Is(err, errUnchanged) {
+			slog.Debug("skipping unreadable sound", "path", file, "error", err)
+		} else {
+			slog.Warn("skipping unreadable sound", "path", file, "error", err)
+		}
+		skip[i] = true
+	}
+
+	slog.Warn("no configured sound can be played, falling back to default")
+	return nil
+}
+
+// load returns a decoded sound file, rendered tone pattern or routine.
+func (p *Player) load(file string) (clip, error) {
+	if name, ok := strings.CutPrefix(file, RoutinePrefix); ok {
+		return p.loadRoutine(name)
+	}
+	pattern, ok := strings.CutPrefix(file, TonePrefix)
+	if !ok {
+		return p.sounds.get(file, p.loadFromFile)
+	}
+
+	if tone, ok := p.tones[pattern]; ok {
+		return tone, nil
+	}
+	tone, err := renderTone(pattern, p.config.Waveform, p.config.Envelope)
+	if err != nil {
+		return nil, err
+	}
+	p.tones[pattern] = tone
+	return tone, nil
+}
+
+// resample converts s from one sample rate to another. It returns s
+// unchanged when the rates already match.
+func resample(s beep.Streamer, from, to beep.SampleRate) beep.Streamer {
+	if from == to {
+		return s
+	}
+	return beep.Resample(resampleQuality, from, to, s)
+}
+
+// Validate decodes the configured sounds and the given extra files or
+// directories into memory and returns every problem Check finds.
+func (p *Player) Validate(files ...string) error {
+	sources := make([]Source, 0, len(files))
+	for _, file := range files {
+		sources = append(sources, Source{Sound: file})
+	}
+	var errs []error
+	for _, problem := range p.Check(sources...) {
+		errs = append(errs, problem)
+	}
+	return errors.Join(errs...)
+}
+
+// Close releases resources held by the player.
+func (p *Player) Close() error {
+	return p.sounds.Close()
+}
+
+// Stop interrupts the sound being played, if any.
 func (p *Player) Stop() {
-	speaker.Clear()
+	p.cancelMu.Lock()
+	defer p.cancelMu.Unlock()
+
+	if p.cancel != nil {
+		p.cancel(errStopped)
+	}
 }
 
-// loadFromFile loads a WAV file from the filesystem.
+// loadFromFile loads a WAV, MP3, OGG Vorbis or FLAC file from the filesystem.
 func (p *Player) loadFromFile(path string) (beep.StreamSeekCloser, beep.Format, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
@@ -139,15 +382,28 @@ func (p *Player) loadFromFile(path string) (beep.StreamSeekCloser, beep.Format,
 		return nil, beep.Format{}, fmt.Errorf("failed to open file: %w", err)
 	}
 
-	streamer, format, err := wav.Decode(f)
+	streamer, format, _, err := Decode(absPath, f)
 	if err != nil {
 		_ = f.Close()
-		return nil, beep.Format{}, fmt.Errorf("failed to decode WAV: %w", err)
+		return nil, beep.Format{}, err
 	}
 
 	return streamer, format, nil
 }
 
+// defaultSound returns the decoded embedded sound, decoding it on first use.
+func (p *Player) defaultSound() (*beep.Buffer, error) {
+	p.embeddedOnce.Do(func() {
+		streamer, format, err := p.loadEmbedded()
+		if err != nil {
+			p.embeddedErr = err
+			return
+		}
+		p.embedded, p.embeddedErr = buffer(streamer, format)
+	})
+	return p.embedded, p.embeddedErr
+}
+
 // loadEmbedded loads the embedded WAV sound.
 func (p *Player) loadEmbedded() (beep.StreamSeekCloser, beep.Format, error) {
 	// Create a NopCloser because bytes.Reader doesn't have Close()
@@ -1,8 +1,17 @@
 package audio
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
 )
 
@@ -32,7 +41,7 @@ func TestPlayer_Play_Disabled(t *testing.T) {
 	}
 	player := NewPlayer(cfg)
 
-	err := player.Play()
+	err := player.Play(context.Background(), "", 0)
 	if err != nil {
 		t.Errorf("expected no error when disabled, got %v", err)
 	}
@@ -67,3 +76,207 @@ func TestPlayer_LoadFromFile_Error(t *testing.T) {
 		t.Error("expected error when loading non-existent file, got nil")
 	}
 }
+
+// sine returns one second of a 440 Hz tone at the given sample rate.
+func sine(rate beep.SampleRate) beep.Streamer {
+	n := rate.N(time.Second)
+	return beep.Take(n, beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		for i := range samples {
+			v := math.Sin(2 * math.Pi * 440 * float64(n) / float64(rate))
+			samples[i] = [2]float64{v, v}
+			n++
+		}
+		return len(samples), true
+	}))
+}
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		from, to beep.SampleRate
+	}{
+		{from: 22050, to: 44100},
+		{from: 44100, to: 44100},
+		{from: 48000, to: 44100},
+		{from: 22050, to: 48000},
+		{from: 44100, to: 48000},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d to %d", tt.from, tt.to), func(t *testing.T) {
+			s := resample(sine(tt.from), tt.from, tt.to)
+
+			var length, crossings int
+			prev := 0.0
+			buf := make([][2]float64, 512)
+			for {
+				n, ok := s.Stream(buf)
+				for _, sample := range buf[:n] {
+					if prev < 0 && sample[0] >= 0 {
+						crossings++
+					}
+					prev = sample[0]
+				}
+				length += n
+				if !ok {
+					break
+				}
+			}
+
+			// One second of input must last one second at the speaker rate
+			if diff := length - tt.to.N(time.Second); diff < -2 || diff > 2 {
+				t.Errorf("expected %d samples, got %d", tt.to.N(time.Second), length)
+			}
+			// The pitch must stay at 440 Hz
+			if crossings < 439 || crossings > 441 {
+				t.Errorf("expected 440 cycles, got %d", crossings)
+			}
+		})
+	}
+}
+
+func TestPlayer_Play_Resample(t *testing.T) {
+	// One second of a 440 Hz tone recorded at 22.05kHz
+	path := filepath.Join(t.TempDir(), "tone.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	format := beep.Format{SampleRate: 22050, NumChannels: 1, Precision: 2}
+	if err := wav.Encode(f, sine(format.SampleRate), format); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	out := &captureOutput{}
+	player := NewPlayer(config.SoundConfig{Enabled: true, Tone: "A4:10ms", Envelope: "flat", Volume: 1}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	// The tone opens the output at 44.1kHz, the file is played at that rate
+	if err := player.Play(context.Background(), "", 0); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if err := player.Play(context.Background(), path, 0); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if out.rate != 44100 {
+		t.Fatalf("expected the output at 44100 Hz, got %d Hz", out.rate)
+	}
+
+	// The file lasts one second and keeps its pitch
+	if diff := len(out.samples) - 44100; diff < -2 || diff > 2 {
+		t.Errorf("expected 44100 samples, got %d", len(out.samples))
+	}
+	crossings := 0
+	for i := 1; i < len(out.samples); i++ {
+		if out.samples[i-1] < 0 && out.samples[i] >= 0 {
+			crossings++
+		}
+	}
+	if crossings < 439 || crossings > 441 {
+		t.Errorf("expected 440 cycles, got %d", crossings)
+	}
+}
+
+// blockingOutput plays until its context is done.
+type blockingOutput struct {
+	started chan struct{}
+}
+
+func (o *blockingOutput) Init(beep.SampleRate) error {
+	return nil
+}
+
+func (o *blockingOutput) Play(ctx context.Context, _ beep.Streamer) error {
+	close(o.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// playAsync starts Play in the background and waits until the output is playing.
+func playAsync(t *testing.T, ctx context.Context, player *Player, out *blockingOutput) <-chan error {
+	t.Helper()
+
+	result := make(chan error, 1)
+	go func() { result <- player.Play(ctx, "", 0) }()
+	select {
+	case <-out.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("playback did not start")
+	}
+	return result
+}
+
+// waitPlay waits for a Play call started by playAsync to return.
+func waitPlay(t *testing.T, result <-chan error, within time.Duration) error {
+	t.Helper()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(within):
+		t.Fatal("Play did not return")
+		return nil
+	}
+}
+
+func TestPlayer_Stop_InterruptsPlayback(t *testing.T) {
+	out := &blockingOutput{started: make(chan struct{})}
+	player := NewPlayer(config.SoundConfig{Enabled: true, Tone: "A4:2s"}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	result := playAsync(t, context.Background(), player, out)
+	player.Stop()
+	if err := waitPlay(t, result, time.Second); err != nil {
+		t.Errorf("expected Stop to end playback cleanly, got %v", err)
+	}
+
+	// Stopping while idle is harmless
+	player.Stop()
+}
+
+func TestPlayer_Play_ContextCancel(t *testing.T) {
+	out := &blockingOutput{started: make(chan struct{})}
+	player := NewPlayer(config.SoundConfig{Enabled: true, Tone: "A4:2s"}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := playAsync(t, ctx, player, out)
+	cancel()
+	if err := waitPlay(t, result, time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestPlayer_Play_MaxDuration(t *testing.T) {
+	t.Run("Cuts long sounds", func(t *testing.T) {
+		out := &captureOutput{}
+		player := NewPlayer(config.SoundConfig{Enabled: true, Tone: "A4:1s", MaxDuration: "100ms"}, WithOutput(out))
+		defer func() { _ = player.Close() }()
+
+		if err := player.Play(context.Background(), "", 0); err != nil {
+			t.Fatalf("Play() error = %v", err)
+		}
+		if len(out.samples) != 4410 {
+			t.Errorf("expected 100ms (4410 samples), got %d", len(out.samples))
+		}
+	})
+
+	t.Run("Abandons a hung output", func(t *testing.T) {
+		out := &blockingOutput{started: make(chan struct{})}
+		player := NewPlayer(config.SoundConfig{Enabled: true, Tone: "A4:1s", MaxDuration: "10ms"}, WithOutput(out))
+		defer func() { _ = player.Close() }()
+
+		result := playAsync(t, context.Background(), player, out)
+		if err := waitPlay(t, result, 2*maxDurationGrace); err != nil {
+			t.Errorf("expected the timeout to end playback cleanly, got %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		player := NewPlayer(config.SoundConfig{MaxDuration: "forever"})
+		defer func() { _ = player.Close() }()
+		if err := player.Validate(); err == nil {
+			t.Error("expected error for invalid max duration")
+		}
+	})
+}
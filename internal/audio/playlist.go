@@ -0,0 +1,177 @@
+package audio
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Playlist orders understood by the sound.order setting.
+const (
+	orderSequential = "sequential"
+	orderRandom     = "random"
+	orderShuffle    = "shuffle"
+)
+
+// playlist rotates through sound files.
+type playlist struct {
+	order string
+	// paths are the configured files and directories
+	paths []string
+	// entries are the expanded files, loaded on first use
+	entries []string
+	loaded  bool
+	// modified are the modification times of the directories in paths
+	// when they were expanded
+	modified map[string]time.Time
+	rand     *rand.Rand
+
+	// pos is the position in entries or perm
+	pos  int
+	perm []int
+	// last is the entry index picked last
+	last int
+}
+
+// newPlaylist creates a playlist of the given files and directories.
+func newPlaylist(order string, paths []string, r *rand.Rand) *playlist {
+	var list []string
+	for _, path := range paths {
+		if path != "" {
+			list = append(list, path)
+		}
+	}
+	return &playlist{order: order, paths: list, rand: r, last: -1}
+}
+
+// validateOrder checks the sound.order setting.
+func validateOrder(order string) error {
+	switch order {
+	case "", orderSequential, orderRandom, orderShuffle:
+		return nil
+	}
+	return fmt.Errorf("invalid sound order %q: must be sequential, random or shuffle", order)
+}
+
+// files returns the playlist entries, expanding directories on first use
+// and again whenever files were added to or removed from one of them.
+func (pl *playlist) files() []string {
+	modified := dirTimes(pl.paths)
+	if !pl.loaded || !maps.Equal(modified, pl.modified) {
+		if pl.loaded {
+			slog.Debug("sound directory changed, reloading playlist", "paths", pl.paths)
+		}
+		pl.entries = expandSounds(pl.paths)
+		pl.modified = modified
+		pl.loaded = true
+		// Start a new round with the new entries
+		pl.perm = nil
+		pl.last = -1
+	}
+	return pl.entries
+}
+
+// next returns the index of the entry to play next, passing over the
+// entries in skip, or -1 if there is none.
+func (pl *playlist) next(skip map[int]bool) int {
+	n := len(pl.files())
+	left := 0
+	for i := range n {
+		if !skip[i] {
+			left++
+		}
+	}
+	if left == 0 {
+		return -1
+	}
+
+	var i int
+	switch pl.order {
+	case orderRandom:
+		k := pl.rand.IntN(left)
+		for i = 0; skip[i] || k > 0; i++ {
+			if !skip[i] {
+				k--
+			}
+		}
+	case orderShuffle:
+		for {
+			if pl.pos >= len(pl.perm) {
+				pl.perm = pl.rand.Perm(n)
+				pl.pos = 0
+				// Don't play the same sound twice in a row across rounds
+				if n > 1 && pl.perm[0] == pl.last {
+					pl.perm[0], pl.perm[n-1] = pl.perm[n-1], pl.perm[0]
+				}
+			}
+			i = pl.perm[pl.pos]
+			pl.pos++
+			if !skip[i] {
+				break
+			}
+		}
+	default:
+		for i = pl.pos % n; skip[i]; i = (i + 1) % n {
+		}
+		pl.pos = i + 1
+	}
+
+	pl.last = i
+	return i
+}
+
+// dirTimes returns the modification times of the directories among paths.
+func dirTimes(paths []string) map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			times[path] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// expandSounds replaces directories with the audio files they contain, in
+// name order. Unreadable directories are skipped with a warning.
+func expandSounds(paths []string) []string {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			// Missing files are reported when they are played
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			slog.Warn("skipping unreadable sound directory", "path", path, "error", err)
+			continue
+		}
+		var found []string
+		for _, entry := range entries {
+			if !entry.IsDir() && isSoundFile(entry.Name()) {
+				found = append(found, filepath.Join(path, entry.Name()))
+			}
+		}
+		if len(found) == 0 {
+			slog.Warn("sound directory contains no audio files", "path", path)
+		}
+		files = append(files, found...)
+	}
+	return files
+}
+
+// isSoundFile reports whether name has the extension of a supported format.
+func isSoundFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".wav", ".wave", ".mp3", ".ogg", ".oga", ".flac":
+		return true
+	}
+	return false
+}
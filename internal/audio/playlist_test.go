@@ -0,0 +1,159 @@
+package audio
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+func TestPlaylist_next(t *testing.T) {
+	files := []string{"a.wav", "b.wav", "c.wav"}
+	newTestPlaylist := func(order string) *playlist {
+		return newPlaylist(order, files, rand.New(rand.NewPCG(1, 2)))
+	}
+
+	t.Run("Sequential", func(t *testing.T) {
+		pl := newTestPlaylist(orderSequential)
+		var got []int
+		for range 5 {
+			got = append(got, pl.next(nil))
+		}
+		if want := []int{0, 1, 2, 0, 1}; !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Shuffle", func(t *testing.T) {
+		pl := newTestPlaylist(orderShuffle)
+		last := -1
+		for round := range 50 {
+			var got []int
+			for range files {
+				i := pl.next(nil)
+				if i == last {
+					t.Fatalf("round %d: entry %d played twice in a row", round, i)
+				}
+				got = append(got, i)
+				last = i
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, []int{0, 1, 2}) {
+				t.Fatalf("round %d: expected every entry once, got %v", round, got)
+			}
+		}
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		pl := newTestPlaylist(orderRandom)
+		seen := make(map[int]bool)
+		for range 100 {
+			i := pl.next(nil)
+			if i < 0 || i >= len(files) {
+				t.Fatalf("index %d out of range", i)
+			}
+			seen[i] = true
+		}
+		if len(seen) != len(files) {
+			t.Errorf("expected every entry to come up, got %v", seen)
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		skip := map[int]bool{1: true}
+		for _, order := range []string{orderSequential, orderShuffle, orderRandom} {
+			pl := newTestPlaylist(order)
+			for range 20 {
+				if i := pl.next(skip); i == 1 || i < 0 {
+					t.Fatalf("%s: expected entry 0 or 2, got %d", order, i)
+				}
+			}
+			if i := pl.next(map[int]bool{0: true, 1: true, 2: true}); i != -1 {
+				t.Errorf("%s: expected -1 with every entry skipped, got %d", order, i)
+			}
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		pl := newPlaylist(orderSequential, []string{""}, nil)
+		if i := pl.next(nil); i != -1 {
+			t.Errorf("expected -1 for an empty playlist, got %d", i)
+		}
+	})
+}
+
+func TestExpandSounds(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.mp3", "a.OGG", "notes.txt", "c.flac"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "more.wav"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got := expandSounds([]string{"bell.wav", dir, "missing.mp3"})
+	want := []string{
+		"bell.wav",
+		filepath.Join(dir, "a.OGG"),
+		filepath.Join(dir, "b.mp3"),
+		filepath.Join(dir, "c.flac"),
+		"missing.mp3",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expandSounds() = %v, want %v", got, want)
+	}
+}
+
+func TestPlayer_nextSound_SkipsUnreadable(t *testing.T) {
+	dir := t.TempDir()
+	copyFixture(t, "tone.flac", dir, "1.flac")
+	if err := os.WriteFile(filepath.Join(dir, "2.mp3"), []byte("not audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	copyFixture(t, "silence.ogg", dir, "3.ogg")
+
+	player := NewPlayer(config.SoundConfig{Enabled: true, File: dir, Order: orderSequential})
+	defer func() { _ = player.Close() }()
+
+	if err := player.Validate(); err == nil {
+		t.Error("expected Validate to report the unreadable file")
+	}
+
+	var rates []int
+	for range 3 {
+		sound := player.nextSound(player.playlistFor(""))
+		if sound == nil {
+			t.Fatal("expected a sound, got the embedded fallback")
+		}
+		rates = append(rates, int(sound.Format().SampleRate))
+	}
+	// The broken file is passed over without playing its neighbor twice
+	if want := []int{22050, 48000, 22050}; !slices.Equal(rates, want) {
+		t.Errorf("expected sample rates %v, got %v", want, rates)
+	}
+}
+
+func TestPlaylist_files_DirectoryChanged(t *testing.T) {
+	dir := t.TempDir()
+	copyFixture(t, "tone.flac", dir, "1.flac")
+	pl := newPlaylist(orderSequential, []string{dir}, nil)
+	if got := pl.files(); len(got) != 1 {
+		t.Fatalf("expected 1 file, got %v", got)
+	}
+
+	// A file added to the directory is picked up
+	copyFixture(t, "silence.ogg", dir, "2.ogg")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(dir, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := pl.files(); len(got) != 2 {
+		t.Errorf("expected 2 files after adding one, got %v", got)
+	}
+}
@@ -0,0 +1,197 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// Preflight policies understood by the sound.preflight setting.
+const (
+	PreflightWarn = "warn"
+	PreflightFail = "fail"
+)
+
+// ambientSetting is the setting of the ambient soundtrack, the only one
+// that may be generated noise.
+const ambientSetting = "sound.ambient.sound"
+
+// Source is a sound as configured, with the setting it comes from.
+type Source struct {
+	// Setting is the configuration key, e.g. "reminder.variants[morning].sound"
+	Setting string
+	// Sound is a file, directory, tone, noise or routine
+	Sound string
+}
+
+// Problem is a configured sound that cannot be played.
+type Problem struct {
+	// Setting is the configuration key the sound comes from
+	Setting string
+	// Path is the absolute path of the file, or the sound as configured if it
+	// is not a file
+	Path string
+	Err  error
+}
+
+// Error implements error.
+func (p Problem) Error() string {
+	switch {
+	case p.Path == "":
+		return fmt.Sprintf("%s: %v", p.Setting, p.Err)
+	case p.Setting == "":
+		return fmt.Sprintf("%s: %v", p.Path, p.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", p.Setting, p.Path, p.Err)
+}
+
+// Unwrap returns the underlying error.
+func (p Problem) Unwrap() error {
+	return p.Err
+}
+
+// Sources returns the sounds configured outside the sound section, which
+// the player checks along with its own.
+func Sources(cfg *config.Config) []Source {
+	var sources []Source
+	for i, v := range cfg.Reminder.Variants {
+		name := v.Name
+		if name == "" {
+			name = fmt.Sprint(i)
+		}
+		sources = append(sources, Source{Setting: fmt.Sprintf("reminder.variants[%s].sound", name), Sound: v.Sound})
+	}
+	return append(sources, Source{Setting: "focus.sound", Sound: cfg.Focus.Sound})
+}
+
+// sources returns the sounds configured in the sound section.
+func (p *Player) sources() []Source {
+	var sources []Source
+	if p.config.Tone != "" {
+		sources = append(sources, Source{Setting: "sound.tone", Sound: TonePrefix + p.config.Tone})
+	} else {
+		sources = append(sources, Source{Setting: "sound.file", Sound: p.config.File})
+		for i, file := range p.config.Files {
+			sources = append(sources, Source{Setting: fmt.Sprintf("sound.files[%d]", i), Sound: file})
+		}
+	}
+	sources = append(sources, Source{Setting: ambientSetting, Sound: p.config.Ambient.Sound})
+	for _, r := range p.config.Routines {
+		sources = append(sources, Source{Setting: fmt.Sprintf("sound.routines[%s]", r.Name), Sound: RoutinePrefix + r.Name})
+	}
+	return sources
+}
+
+// Check reports every problem with the sound settings and decodes every
+// configured sound, along with the given sources, into memory. Files are
+// reported by their absolute path. Sounds are only loaded if sound is
+// enabled.
+func (p *Player) Check(sources ...Source) []Problem {
+	var problems []Problem
+	setting := func(name string, err error) {
+		if err != nil {
+			problems = append(problems, Problem{Setting: name, Err: err})
+		}
+	}
+
+	_, _, err := fades(p.config)
+	setting("sound", err)
+	_, err = maxDuration(p.config)
+	setting("sound.max_duration", err)
+	setting("sound.max_volume", validateMaxVolume(p.config.MaxVolume))
+	setting("sound.order", validateOrder(p.config.Order))
+	setting("sound.backend", p.outputErr)
+	setting("sound.fallback", validateFallback(p.config.Fallback))
+	setting("sound.speech", validateSpeech(p.config.Speech))
+	setting("sound.preflight", validatePreflight(p.config.Preflight))
+	_, _, err = ambientFades(p.config.Ambient)
+	setting("sound.ambient", err)
+
+	if !p.config.Enabled {
+		return problems
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, source := range append(p.sources(), sources...) {
+		problems = append(problems, p.checkSource(source)...)
+	}
+	return problems
+}
+
+// checkSource loads every sound a setting refers to.
+func (p *Player) checkSource(source Source) []Problem {
+	sound := source.Sound
+	if sound == "" || sound == "bell.wav" {
+		return nil
+	}
+	if color, ok := strings.CutPrefix(sound, NoisePrefix); ok && source.Setting == ambientSetting {
+		if _, err := newNoise(color); err != nil {
+			return []Problem{{Setting: source.Setting, Path: sound, Err: err}}
+		}
+		return nil
+	}
+	if strings.HasPrefix(sound, TonePrefix) || strings.HasPrefix(sound, RoutinePrefix) {
+		if _, err := p.load(sound); err != nil {
+			return []Problem{{Setting: source.Setting, Path: sound, Err: err}}
+		}
+		return nil
+	}
+
+	files := expandSounds([]string{sound})
+	if len(files) == 0 {
+		return []Problem{{Setting: source.Setting, Path: absPath(sound), Err: errors.New("no audio files found")}}
+	}
+	var problems []Problem
+	for _, file := range files {
+		if _, err := p.load(file); err != nil {
+			problems = append(problems, Problem{Setting: source.Setting, Path: absPath(file), Err: err})
+		}
+	}
+	return problems
+}
+
+// Preflight checks the configured sounds and the given sources before the
+// first reminder. Each problem is logged; with the fail policy they are
+// also returned, so the caller can refuse to start, otherwise the sounds
+// are skipped when played.
+func (p *Player) Preflight(sources ...Source) error {
+	problems := p.Check(sources...)
+	errs := make([]error, 0, len(problems))
+	for _, problem := range problems {
+		slog.Warn("sound cannot be played", "setting", problem.Setting, "path", problem.Path, "error", problem.Err)
+		errs = append(errs, problem)
+	}
+	if len(problems) == 0 {
+		slog.Debug("sound preflight passed")
+		return nil
+	}
+	if p.config.Preflight != PreflightFail {
+		slog.Warn("sounds that cannot be played will be skipped", "problems", len(problems))
+		return nil
+	}
+	return fmt.Errorf("%d configured sounds cannot be played: %w", len(problems), errors.Join(errs...))
+}
+
+// validatePreflight checks the sound.preflight setting.
+func validatePreflight(policy string) error {
+	switch policy {
+	case "", PreflightWarn, PreflightFail:
+		return nil
+	}
+	return fmt.Errorf("invalid sound preflight %q: must be warn or fail", policy)
+}
+
+// absPath returns the absolute form of path, or path itself if it cannot
+// be made absolute.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,117 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+func TestPlayer_Check(t *testing.T) {
+	testdata := filepath.Join(wd(t), "testdata")
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.wav")
+	if err := os.WriteFile(corrupt, []byte("not a wav file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty")
+	if err := os.Mkdir(empty, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Relative paths are reported in full
+	t.Chdir(dir)
+	player := NewPlayer(config.SoundConfig{
+		Enabled:   true,
+		Backend:   "null",
+		File:      "missing.mp3",
+		Files:     []string{"../" + filepath.Base(dir) + "/corrupt.wav", "tone:C5:50ms"},
+		Fallback:  "siren",
+		MaxVolume: -0.5,
+		Ambient:   config.AmbientConfig{Sound: "empty"},
+	})
+	defer func() { _ = player.Close() }()
+
+	problems := player.Check(Sources(&config.Config{
+		Reminder: config.ReminderConfig{Variants: []config.VariantConfig{
+			{Name: "morning", Sound: "tone:H9:1s"},
+			{Sound: filepath.Join(testdata, "tone.flac")},
+		}},
+		Focus: config.FocusConfig{Sound: "routine:yoga"},
+	})...)
+
+	want := []struct {
+		setting, path, err string
+	}{
+		{setting: "sound.max_volume", err: "negative"},
+		{setting: "sound.fallback", err: "siren"},
+		{setting: "sound.file", path: filepath.Join(dir, "missing.mp3"), err: "no such file"},
+		{setting: "sound.files[0]", path: corrupt},
+		{setting: "sound.ambient.sound", path: empty, err: "no audio files"},
+		{setting: "reminder.variants[morning].sound", path: "tone:H9:1s"},
+		{setting: "focus.sound", path: "routine:yoga", err: "unknown routine"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %d: %v", len(want), len(problems), problems)
+	}
+	for i, w := range want {
+		got := problems[i]
+		if got.Setting != w.setting || got.Path != w.path || !strings.Contains(got.Err.Error(), w.err) {
+			t.Errorf("problem %d: expected %s: %s: %s, got %v", i, w.setting, w.path, w.err, got)
+		}
+	}
+}
+
+// wd returns the working directory the test started in.
+func wd(t *testing.T) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestPlayer_Check_Disabled(t *testing.T) {
+	player := NewPlayer(config.SoundConfig{
+		File:      "missing.mp3",
+		Preflight: "sometimes",
+	})
+	defer func() { _ = player.Close() }()
+
+	// Settings are still checked, but nothing is loaded
+	problems := player.Check()
+	if len(problems) != 1 || problems[0].Setting != "sound.preflight" {
+		t.Errorf("expected only the preflight setting to be reported, got %v", problems)
+	}
+}
+
+func TestPlayer_Preflight(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		file    string
+		wantErr bool
+	}{
+		{name: "Warn", policy: PreflightWarn, file: "testdata/missing.wav"},
+		{name: "Fail", policy: PreflightFail, file: "testdata/missing.wav", wantErr: true},
+		{name: "Fail with playable sounds", policy: PreflightFail, file: "testdata/tone.flac"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := NewPlayer(config.SoundConfig{Enabled: true, Backend: "null", File: tt.file, Preflight: tt.policy})
+			defer func() { _ = player.Close() }()
+
+			err := player.Preflight()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Preflight() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), filepath.Join(wd(t), tt.file)) {
+				t.Errorf("expected the error to name the full path, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// ErrUnavailable is returned when no sound could be played because the
+// audio output is not working.
+var ErrUnavailable = errors.New("audio output unavailable")
+
+// ErrDeviceLost is returned by an Output when the device stopped working
+// during playback; the player initializes it again for the next sound.
+var ErrDeviceLost = errors.New("audio device lost")
+
+// Backoff limits for retrying output initialization.
+const (
+	initialBackoff = 5 * time.Second
+	maxBackoff     = 5 * time.Minute
+)
+
+// Fallbacks used while audio is unavailable.
+const (
+	fallbackNone         = "none"
+	fallbackBell         = "bell"
+	fallbackNotification = "notification"
+)
+
+// validateFallback checks the sound.fallback setting.
+func validateFallback(fallback string) error {
+	switch fallback {
+	case "", fallbackNone, fallbackBell, fallbackNotification:
+		return nil
+	}
+	return fmt.Errorf("invalid sound fallback %q: must be none, bell or notification", fallback)
+}
+
+// ensureOutput initializes the output if needed. Failed attempts are
+// retried on later sounds, waiting longer after each failure, so audio
+// starts working once the sound server comes up.
+func (p *Player) ensureOutput(rate beep.SampleRate) error {
+	if p.ready {
+		return nil
+	}
+
+	now := p.now()
+	if now.Before(p.retryAt) {
+		return fmt.Errorf("%w: retrying in %s", ErrUnavailable, p.retryAt.Sub(now).Round(time.Second))
+	}
+
+	if err := p.output.Init(rate); err != nil {
+		p.backoff = min(max(2*p.backoff, initialBackoff), maxBackoff)
+		p.retryAt = now.Add(p.backoff)
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	if p.backoff > 0 {
+		slog.Info("audio output recovered")
+	}
+	p.ready = true
+	p.rate = rate
+	p.backoff = 0
+	p.retryAt = time.Time{}
+	return nil
+}
+
+// fallback alerts the user in the configured way when a sound could not be
+// played. It returns cause if there is no fallback or it failed too.
+func (p *Player) fallback(cause error) error {
+	switch p.config.Fallback {
+	case fallbackBell:
+		if _, err := fmt.Fprint(p.bell, "\a"); err != nil {
+			return errors.Join(cause, fmt.Errorf("failed to ring terminal bell: %w", err))
+		}
+	case fallbackNotification:
+		if p.alert == nil {
+			return cause
+		}
+		if err := p.alert(); err != nil {
+			return errors.Join(cause, err)
+		}
+	default:
+		return cause
+	}
+
+	slog.Warn("sound unavailable, used fallback", "fallback", p.config.Fallback, "error", cause)
+	return nil
+}
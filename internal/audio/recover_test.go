@@ -0,0 +1,152 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// flakyOutput fails to initialize a number of times and can lose its device.
+type flakyOutput struct {
+	captureOutput
+	failures int
+	attempts int
+	lose     bool
+}
+
+func (o *flakyOutput) Init(rate beep.SampleRate) error {
+	o.attempts++
+	if o.failures > 0 {
+		o.failures--
+		return errors.New("no sound server")
+	}
+	return o.captureOutput.Init(rate)
+}
+
+func (o *flakyOutput) Play(ctx context.Context, s beep.Streamer) error {
+	if o.lose {
+		o.lose = false
+		return ErrDeviceLost
+	}
+	return o.captureOutput.Play(ctx, s)
+}
+
+// newFlakyPlayer returns a player on out with a controllable clock.
+func newFlakyPlayer(t *testing.T, out Output, fallback string) (*Player, *time.Time, *bytes.Buffer) {
+	t.Helper()
+
+	now := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+	var bell bytes.Buffer
+	p := NewPlayer(config.SoundConfig{Enabled: true, Tone: "C5:10ms", Fallback: fallback}, WithOutput(out))
+	p.now = func() time.Time { return now }
+	p.bell = &bell
+	t.Cleanup(func() { _ = p.Close() })
+	return p, &now, &bell
+}
+
+func TestPlayer_RetriesInitWithBackoff(t *testing.T) {
+	out := &flakyOutput{failures: 3}
+	p, now, _ := newFlakyPlayer(t, out, fallbackNone)
+
+	steps := []struct {
+		advance  time.Duration
+		attempts int
+		played   bool
+	}{
+		{advance: 0, attempts: 1},               // fails, next try in 5s
+		{advance: 4 * time.Second, attempts: 1}, // still backing off
+		{advance: time.Second, attempts: 2},     // fails, next try in 10s
+		{advance: 9 * time.Second, attempts: 2}, // still backing off
+		{advance: time.Second, attempts: 3},     // fails, next try in 20s
+		{advance: 20 * time.Second, attempts: 4, played: true},
+		{advance: time.Second, attempts: 4, played: true}, // stays initialized
+	}
+
+	for i, step := range steps {
+		*now = now.Add(step.advance)
+		err := p.Play(context.Background(), "", 0)
+		if step.played && err != nil {
+			t.Fatalf("step %d: Play() error = %v", i, err)
+		}
+		if !step.played && !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("step %d: expected ErrUnavailable, got %v", i, err)
+		}
+		if out.attempts != step.attempts {
+			t.Fatalf("step %d: expected %d init attempts, got %d", i, step.attempts, out.attempts)
+		}
+	}
+}
+
+func TestPlayer_BackoffIsCapped(t *testing.T) {
+	out := &flakyOutput{failures: 100}
+	p, now, _ := newFlakyPlayer(t, out, fallbackNone)
+
+	for range 20 {
+		_ = p.Play(context.Background(), "", 0)
+		*now = now.Add(maxBackoff)
+	}
+	if p.backoff != maxBackoff {
+		t.Errorf("expected backoff capped at %s, got %s", maxBackoff, p.backoff)
+	}
+	if out.attempts != 20 {
+		t.Errorf("expected a retry every %s, got %d attempts", maxBackoff, out.attempts)
+	}
+}
+
+func TestPlayer_ReinitAfterDeviceLoss(t *testing.T) {
+	out := &flakyOutput{}
+	p, _, bell := newFlakyPlayer(t, out, fallbackBell)
+
+	if err := p.Play(context.Background(), "", 0); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	out.lose = true
+	if err := p.Play(context.Background(), "", 0); err != nil {
+		t.Fatalf("expected the bell fallback to cover device loss, got %v", err)
+	}
+	if bell.String() != "\a" {
+		t.Errorf("expected the terminal bell, got %q", bell.String())
+	}
+
+	// The next sound reopens the device right away
+	if err := p.Play(context.Background(), "", 0); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if out.attempts != 2 || out.plays != 2 {
+		t.Errorf("expected 2 inits and 2 plays, got %d and %d", out.attempts, out.plays)
+	}
+}
+
+func TestPlayer_Fallback(t *testing.T) {
+	t.Run("Notification", func(t *testing.T) {
+		alerts := 0
+		p := NewPlayer(config.SoundConfig{Enabled: true, Tone: "A4:10ms", Fallback: fallbackNotification},
+			WithOutput(&flakyOutput{failures: 1}),
+			WithAlert(func() error {
+				alerts++
+				return nil
+			}),
+		)
+		defer func() { _ = p.Close() }()
+
+		if err := p.Play(context.Background(), "", 0); err != nil {
+			t.Errorf("Play() error = %v", err)
+		}
+		if alerts != 1 {
+			t.Errorf("expected 1 alert, got %d", alerts)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		p := NewPlayer(config.SoundConfig{Fallback: "smoke signal"})
+		defer func() { _ = p.Close() }()
+		if err := p.Validate(); err == nil {
+			t.Error("expected error for unknown fallback")
+		}
+	})
+}
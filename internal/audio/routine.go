@@ -0,0 +1,242 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// RoutinePrefix marks sounds that are guided break routines rather than
+// files, e.g. "routine:breathing".
+const RoutinePrefix = "routine:"
+
+// finishChime is played at the end of the built-in routines.
+const finishChime = TonePrefix + "C5:200ms, E5:200ms, G5:400ms"
+
+// builtinRoutines are available without being configured.
+var builtinRoutines = []config.RoutineConfig{
+	{
+		// 4-7-8 breathing: rising notes while breathing in, a tick to hold
+		// and falling notes while breathing out
+		Name: "breathing",
+		Steps: []config.RoutineStepConfig{
+			{Name: "inhale", Sound: TonePrefix + "C4:1s, E4:1s, G4:1s, C5:1s", Duration: "4s"},
+			{Name: "hold", Sound: TonePrefix + "G4:150ms", Duration: "7s"},
+			{Name: "exhale", Sound: TonePrefix + "C5:2s, G4:2s, E4:2s, C4:2s", Duration: "8s"},
+		},
+		Repeat: 4,
+		End:    finishChime,
+	},
+	{
+		Name: "neck-stretch",
+		Steps: []config.RoutineStepConfig{
+			{Name: "stretch one side", Sound: TonePrefix + "E5:300ms", Duration: "30s"},
+			{Name: "switch sides", Sound: TonePrefix + "E5:150ms, rest:100ms, E5:150ms", Duration: "30s"},
+		},
+		Repeat: 2,
+		End:    finishChime,
+	},
+}
+
+// clip is a sound that can be played from any position: a decoded file, a
+// rendered tone or a routine.
+type clip interface {
+	Format() beep.Format
+	Len() int
+	Streamer(from, to int) beep.StreamSeeker
+}
+
+// routine is a guided break: cues separated by silence, timed by the
+// routine rather than by the length of the cues.
+type routine struct {
+	format beep.Format
+	steps  []routineStep
+	length int
+}
+
+// routineStep is a cue followed by silence until the step's length.
+type routineStep struct {
+	// cue is nil for silent steps
+	cue    clip
+	length int
+}
+
+// Format returns the format of the routine.
+func (r *routine) Format() beep.Format {
+	return r.format
+}
+
+// Len returns the length of the routine in samples.
+func (r *routine) Len() int {
+	return r.length
+}
+
+// Duration returns how long the routine takes.
+func (r *routine) Duration() time.Duration {
+	return r.format.SampleRate.D(r.length)
+}
+
+// Streamer returns a streamer over the samples [from, to) of the routine.
+func (r *routine) Streamer(from, to int) beep.StreamSeeker {
+	return &routineStreamer{r: r, from: from, to: to, pos: from}
+}
+
+// routineStreamer plays a range of a routine, producing the cues and the
+// silence between them as they are reached.
+type routineStreamer struct {
+	r        *routine
+	from, to int
+	pos      int
+	// step is the index of the step at pos, which starts at stepStart
+	step, stepStart int
+}
+
+// Stream implements beep.Streamer.
+func (s *routineStreamer) Stream(samples [][2]float64) (int, bool) {
+	filled := 0
+	for filled < len(samples) && s.pos < s.to {
+		step, offset := s.locate()
+		n := min(len(samples)-filled, step.length-offset, s.to-s.pos)
+		if step.cue != nil && offset < step.cue.Len() {
+			n = min(n, step.cue.Len()-offset)
+			n, _ = step.cue.Streamer(offset, offset+n).Stream(samples[filled : filled+n])
+		} else {
+			clear(samples[filled : filled+n])
+		}
+		filled += n
+		s.pos += n
+	}
+	return filled, filled > 0
+}
+
+// locate returns the step at the current position and the offset into it.
+func (s *routineStreamer) locate() (routineStep, int) {
+	// Search from the start again after seeking backwards
+	if s.pos < s.stepStart {
+		s.step, s.stepStart = 0, 0
+	}
+	for s.pos-s.stepStart >= s.r.steps[s.step].length {
+		s.stepStart += s.r.steps[s.step].length
+		s.step++
+	}
+	return s.r.steps[s.step], s.pos - s.stepStart
+}
+
+// Err implements beep.Streamer.
+func (s *routineStreamer) Err() error {
+	return nil
+}
+
+// Len implements beep.StreamSeeker.
+func (s *routineStreamer) Len() int {
+	return s.to - s.from
+}
+
+// Position implements beep.StreamSeeker.
+func (s *routineStreamer) Position() int {
+	return s.pos - s.from
+}
+
+// Seek implements beep.StreamSeeker.
+func (s *routineStreamer) Seek(p int) error {
+	if p < 0 || p > s.Len() {
+		return fmt.Errorf("seek position %v out of range [%v, %v]", p, 0, s.Len())
+	}
+	s.pos = s.from + p
+	return nil
+}
+
+// findRoutine returns the configured routine with the given name, or the
+// built-in one.
+func (p *Player) findRoutine(name string) (config.RoutineConfig, error) {
+	for _, r := range p.config.Routines {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	for _, r := range builtinRoutines {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return config.RoutineConfig{}, fmt.Errorf("unknown routine %q", name)
+}
+
+// loadRoutine builds a routine from its definition. Cues are loaded like
+// any other sound, so files are cached and reloaded when they change.
+func (p *Player) loadRoutine(name string) (*routine, error) {
+	cfg, err := p.findRoutine(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Steps) == 0 {
+		return nil, fmt.Errorf("routine %q has no steps", name)
+	}
+	if cfg.Repeat < 0 {
+		return nil, fmt.Errorf("routine %q: invalid repeat %d", name, cfg.Repeat)
+	}
+
+	r := &routine{format: beep.Format{SampleRate: toneRate, NumChannels: 2, Precision: 2}}
+	var steps []routineStep
+	for i, step := range cfg.Steps {
+		s, err := p.routineStep(step.Sound, step.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("routine %q: step %d (%s): %w", name, i+1, step.Name, err)
+		}
+		steps = append(steps, s)
+	}
+	for range max(cfg.Repeat, 1) {
+		r.steps = append(r.steps, steps...)
+	}
+	if cfg.End != "" {
+		s, err := p.routineStep(cfg.End, "")
+		if err != nil {
+			return nil, fmt.Errorf("routine %q: end: %w", name, err)
+		}
+		r.steps = append(r.steps, s)
+	}
+
+	for _, s := range r.steps {
+		r.length += s.length
+	}
+	return r, nil
+}
+
+// routineStep loads the cue of a step and works out its length.
+func (p *Player) routineStep(sound, duration string) (routineStep, error) {
+	var step routineStep
+	if strings.HasPrefix(sound, RoutinePrefix) {
+		return step, errors.New("routines cannot be nested")
+	}
+	if sound != "" {
+		cue, err := p.load(sound)
+		if err != nil {
+			return step, err
+		}
+		// Cues at other rates are converted once, so the routine can be
+		// streamed without resampling as it goes
+		if rate := cue.Format().SampleRate; rate != toneRate {
+			buf := beep.NewBuffer(beep.Format{SampleRate: toneRate, NumChannels: 2, Precision: 2})
+			buf.Append(resample(cue.Streamer(0, cue.Len()), rate, toneRate))
+			cue = buf
+		}
+		step.cue = cue
+		step.length = cue.Len()
+	}
+
+	if duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil || d <= 0 {
+			return step, fmt.Errorf("invalid duration %q", duration)
+		}
+		step.length = toneRate.N(d)
+	}
+	if step.length == 0 {
+		return step, errors.New("needs a sound or a duration")
+	}
+	return step, nil
+}
@@ -0,0 +1,193 @@
+package audio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+func TestPlayer_loadRoutine_Builtin(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+	}{
+		// 4 rounds of 4s in, 7s hold and 8s out, then the finishing chime
+		{name: "breathing", duration: 4*19*time.Second + 800*time.Millisecond},
+		{name: "neck-stretch", duration: 2*60*time.Second + 800*time.Millisecond},
+	}
+
+	player := NewPlayer(config.SoundConfig{Enabled: true})
+	defer func() { _ = player.Close() }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := player.loadRoutine(tt.name)
+			if err != nil {
+				t.Fatalf("loadRoutine() error = %v", err)
+			}
+			if r.Duration() != tt.duration {
+				t.Errorf("expected %v, got %v", tt.duration, r.Duration())
+			}
+		})
+	}
+}
+
+func TestPlayer_Play_Routine(t *testing.T) {
+	out := &captureOutput{}
+	player := NewPlayer(config.SoundConfig{
+		Enabled:     true,
+		File:        "routine:count",
+		Envelope:    "flat",
+		Volume:      1,
+		MaxDuration: "100ms",
+		Routines: []config.RoutineConfig{{
+			Name: "count",
+			Steps: []config.RoutineStepConfig{
+				{Name: "tick", Sound: "tone:A4:100ms", Duration: "300ms"},
+				{Name: "wait", Duration: "200ms"},
+				{Name: "cut", Sound: "tone:A5:1s", Duration: "50ms"},
+			},
+			Repeat: 2,
+			End:    "tone:C5:100ms",
+		}},
+	}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	// The routine takes as long as it says, regardless of max_duration
+	if err := player.Play(context.Background(), "", 0); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	ms := func(d int) int { return toneRate.N(time.Duration(d) * time.Millisecond) }
+	if len(out.samples) != ms(2*550+100) {
+		t.Fatalf("expected 1.2s, got %d samples", len(out.samples))
+	}
+
+	sections := []struct {
+		from, to int
+		sound    bool
+	}{
+		{0, 100, true},
+		{100, 500, false},
+		{500, 550, true},
+		{550, 650, true},
+		{650, 1050, false},
+		{1050, 1100, true},
+		{1100, 1200, true},
+	}
+	for _, s := range sections {
+		// Skip the edges where notes ramp up and down
+		p := peak(out.samples[ms(s.from)+100 : ms(s.to)-100])
+		if s.sound && p < 0.1 {
+			t.Errorf("expected a cue from %dms to %dms, got peak %.3f", s.from, s.to, p)
+		}
+		if !s.sound && p != 0 {
+			t.Errorf("expected silence from %dms to %dms, got peak %.3f", s.from, s.to, p)
+		}
+	}
+}
+
+func TestPlayer_Stop_Routine(t *testing.T) {
+	out := &blockingOutput{started: make(chan struct{})}
+	player := NewPlayer(config.SoundConfig{Enabled: true, File: "routine:breathing"}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	// Skipping a break ends a routine right away rather than after a minute
+	result := playAsync(t, context.Background(), player, out)
+	player.Stop()
+	if err := waitPlay(t, result, time.Second); err != nil {
+		t.Errorf("expected Stop to end the routine cleanly, got %v", err)
+	}
+}
+
+func TestPlayer_loadRoutine_FileCue(t *testing.T) {
+	player := NewPlayer(config.SoundConfig{
+		Enabled: true,
+		Routines: []config.RoutineConfig{{
+			Name:  "file",
+			Steps: []config.RoutineStepConfig{{Sound: "testdata/tone.flac"}},
+		}},
+	})
+	defer func() { _ = player.Close() }()
+
+	// The 22.05kHz cue is converted to the routine's rate and sets the
+	// step length
+	r, err := player.loadRoutine("file")
+	if err != nil {
+		t.Fatalf("loadRoutine() error = %v", err)
+	}
+	if r.Format().SampleRate != toneRate || r.Duration() != 100*time.Millisecond {
+		t.Errorf("expected 100ms at %d Hz, got %v at %d Hz", toneRate, r.Duration(), r.Format().SampleRate)
+	}
+}
+
+func TestRoutineStreamer_Seek(t *testing.T) {
+	player := NewPlayer(config.SoundConfig{Enabled: true})
+	defer func() { _ = player.Close() }()
+
+	r, err := player.loadRoutine("breathing")
+	if err != nil {
+		t.Fatalf("loadRoutine() error = %v", err)
+	}
+
+	read := func(from, n int) [][2]float64 {
+		s := r.Streamer(0, r.Len())
+		if err := s.Seek(from); err != nil {
+			t.Fatalf("Seek() error = %v", err)
+		}
+		buf := make([][2]float64, n)
+		if got, _ := s.Stream(buf); got != n {
+			t.Fatalf("expected %d samples, got %d", n, got)
+		}
+		return buf
+	}
+
+	// Reading across the end of the first inhale matches reading it in one go
+	start := toneRate.N(3 * time.Second)
+	all := read(start, toneRate.N(2*time.Second))
+	later := read(start+toneRate.N(time.Second)-10, 20)
+	for i, v := range later {
+		if v != all[toneRate.N(time.Second)-10+i] {
+			t.Fatalf("sample %d differs after seeking", i)
+		}
+	}
+
+	if err := r.Streamer(0, r.Len()).Seek(r.Len() + 1); err == nil {
+		t.Error("expected error seeking past the end")
+	}
+}
+
+func TestPlayer_Validate_Routines(t *testing.T) {
+	tests := []struct {
+		name     string
+		routines []config.RoutineConfig
+		wantErr  bool
+	}{
+		{name: "Builtin"},
+		{name: "Override builtin", routines: []config.RoutineConfig{{Name: "breathing", Steps: []config.RoutineStepConfig{{Duration: "1s"}}}}},
+		{name: "No steps", routines: []config.RoutineConfig{{Name: "breathing"}}, wantErr: true},
+		{name: "Empty step", routines: []config.RoutineConfig{{Name: "breathing", Steps: []config.RoutineStepConfig{{Name: "nothing"}}}}, wantErr: true},
+		{name: "Invalid duration", routines: []config.RoutineConfig{{Name: "breathing", Steps: []config.RoutineStepConfig{{Duration: "long"}}}}, wantErr: true},
+		{name: "Nested", routines: []config.RoutineConfig{{Name: "breathing", Steps: []config.RoutineStepConfig{{Sound: "routine:neck-stretch"}}}}, wantErr: true},
+		{name: "Missing cue", routines: []config.RoutineConfig{{Name: "breathing", Steps: []config.RoutineStepConfig{{Sound: "testdata/missing.wav"}}}}, wantErr: true},
+		{name: "Negative repeat", routines: []config.RoutineConfig{{Name: "breathing", Steps: []config.RoutineStepConfig{{Duration: "1s"}}, Repeat: -1}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := NewPlayer(config.SoundConfig{Enabled: true, Backend: "null", Routines: tt.routines})
+			defer func() { _ = player.Close() }()
+
+			if err := player.Validate("routine:breathing"); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	player := NewPlayer(config.SoundConfig{Enabled: true, Backend: "null"})
+	defer func() { _ = player.Close() }()
+	if err := player.Validate("routine:yoga"); err == nil {
+		t.Error("expected error for unknown routine")
+	}
+}
@@ -0,0 +1,137 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// maxSpeechStderr is how much of the speech command's error output is kept
+// for error messages.
+const maxSpeechStderr = 512
+
+// speechTimeout parses how long the speech command may take; zero means
+// no limit.
+func speechTimeout(cfg config.SpeechConfig) (time.Duration, error) {
+	if cfg.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(cfg.Timeout)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid speech timeout %q", cfg.Timeout)
+	}
+	return d, nil
+}
+
+// validateSpeech checks that the speech command can be run.
+func validateSpeech(cfg config.SpeechConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if _, err := speechTimeout(cfg); err != nil {
+		return err
+	}
+	if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+		return errors.New("speech is enabled but no speech command is set")
+	}
+	if _, err := exec.LookPath(cfg.Command[0]); err != nil {
+		return fmt.Errorf("speech command %q not found: %w", cfg.Command[0], err)
+	}
+	return nil
+}
+
+// Speak reads text aloud with the speech command and plays the result like
+// any other sound, at the given volume or the configured one if zero. It
+// does nothing if speech is disabled or text is empty.
+func (p *Player) Speak(ctx context.Context, text string, volume float64) error {
+	if !p.config.Enabled || !p.config.Speech.Enabled || strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	// Synthesize before taking the lock, so a slow command doesn't hold up
+	// other sounds
+	sound, err := p.synthesize(ctx, text)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.playClip(ctx, sound, volume)
+}
+
+// synthesize runs the speech command with text on stdin and decodes the
+// audio it writes to stdout.
+func (p *Player) synthesize(ctx context.Context, text string) (*beep.Buffer, error) {
+	cfg := p.config.Speech
+	if len(cfg.Command) == 0 {
+		return nil, errors.New("no speech command is set")
+	}
+
+	timeout, err := speechTimeout(cfg)
+	if err != nil {
+		slog.Warn("ignoring speech timeout", "error", err)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var stdout bytes.Buffer
+	stderr := &limitedBuffer{max: maxSpeechStderr}
+	cmd := exec.CommandContext(ctx, cfg.Command[0], cfg.Command[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("speech command failed: %w", err)
+	}
+	slog.Debug("speech synthesized", "bytes", stdout.Len(), "took", time.Since(start).Round(time.Millisecond))
+
+	streamer, format, _, err := Decode("speech", nopSeekCloser{bytes.NewReader(stdout.Bytes())})
+	if err != nil {
+		return nil, fmt.Errorf("speech command output: %w", err)
+	}
+	return buffer(streamer, format)
+}
+
+// nopSeekCloser adds a no-op Close to an in-memory reader.
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+// Close does nothing.
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
+// limitedBuffer keeps the first max bytes written to it and discards the
+// rest.
+type limitedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+// Write implements io.Writer.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
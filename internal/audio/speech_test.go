@@ -0,0 +1,175 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// speechSamplesPerChar is how long the stub speech command talks for each
+// character of text, at 22.05kHz.
+const speechSamplesPerChar = 100
+
+// TestSpeechHelper is not a real test: it is run as a stub speech command
+// that reads text on stdin and writes a WAV to stdout whose length depends
+// on the text.
+func TestSpeechHelper(t *testing.T) {
+	if os.Getenv("RTR_SPEECH_HELPER") != "1" {
+		t.Skip("only run as a stub speech command")
+	}
+
+	text, _ := io.ReadAll(os.Stdin)
+	if strings.Contains(string(text), "fail") {
+		fmt.Fprintln(os.Stderr, "voice not found")
+		os.Exit(1)
+	}
+	if strings.Contains(string(text), "slow") {
+		time.Sleep(time.Minute)
+	}
+
+	// wav.Encode needs to seek, so render to a file first
+	f, err := os.CreateTemp("", "speech-*.wav")
+	if err != nil {
+		os.Exit(2)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	n := len(text) * speechSamplesPerChar
+	voice := beep.Take(n, beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		for i := range samples {
+			samples[i] = [2]float64{0.5, 0.5}
+		}
+		return len(samples), true
+	}))
+	if err := wav.Encode(f, voice, beep.Format{SampleRate: 22050, NumChannels: 1, Precision: 2}); err != nil {
+		os.Exit(2)
+	}
+	_, _ = f.Seek(0, io.SeekStart)
+	_, _ = io.Copy(os.Stdout, f)
+	os.Exit(0)
+}
+
+// stubSpeech returns speech settings that run TestSpeechHelper.
+func stubSpeech(t *testing.T) config.SpeechConfig {
+	t.Helper()
+	t.Setenv("RTR_SPEECH_HELPER", "1")
+	return config.SpeechConfig{
+		Enabled: true,
+		Command: []string{os.Args[0], "-test.run=^TestSpeechHelper$"},
+		Timeout: "10s",
+	}
+}
+
+func TestPlayer_Speak(t *testing.T) {
+	out := &captureOutput{}
+	player := NewPlayer(config.SoundConfig{
+		Enabled:   true,
+		Envelope:  "flat",
+		Volume:    1,
+		MaxVolume: 0.5,
+		Speech:    stubSpeech(t),
+	}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	text := "Time to stand up"
+	if err := player.Speak(context.Background(), text, 0); err != nil {
+		t.Fatalf("Speak() error = %v", err)
+	}
+
+	// The speech is played like any other sound, with the volume capped. The
+	// WAV decoder may drop the final sample.
+	if n := len(text) * speechSamplesPerChar; out.rate != 22050 || len(out.samples) < n-1 || len(out.samples) > n {
+		t.Fatalf("expected %d samples at 22050 Hz, got %d at %d Hz", len(text)*speechSamplesPerChar, len(out.samples), out.rate)
+	}
+	// beep's WAV decoder reads 16-bit samples at half their level
+	want := 0.25 * amplitude(0.5)
+	if got := peak(out.samples); got < want-0.01 || got > want+0.01 {
+		t.Errorf("expected peak %.3f, got %.3f", want, got)
+	}
+}
+
+func TestPlayer_Speak_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		timeout string
+		want    string
+	}{
+		{name: "Command fails", text: "please fail", want: "voice not found"},
+		{name: "Timeout", text: "slow", timeout: "200ms", want: "speech command failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			speech := stubSpeech(t)
+			if tt.timeout != "" {
+				speech.Timeout = tt.timeout
+			}
+			out := &captureOutput{}
+			player := NewPlayer(config.SoundConfig{Enabled: true, Speech: speech}, WithOutput(out))
+			defer func() { _ = player.Close() }()
+
+			err := player.Speak(context.Background(), tt.text, 0)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+			if out.plays != 0 {
+				t.Errorf("expected nothing to play, got %d plays", out.plays)
+			}
+		})
+	}
+
+	t.Run("Not audio", func(t *testing.T) {
+		player := NewPlayer(config.SoundConfig{
+			Enabled: true,
+			Speech:  config.SpeechConfig{Enabled: true, Command: []string{"echo", "hello"}},
+		}, WithOutput(&captureOutput{}))
+		defer func() { _ = player.Close() }()
+
+		if err := player.Speak(context.Background(), "hello", 0); err == nil {
+			t.Error("expected error for output that is not audio")
+		}
+	})
+}
+
+func TestPlayer_Speak_Disabled(t *testing.T) {
+	out := &captureOutput{}
+	speech := stubSpeech(t)
+	speech.Enabled = false
+	player := NewPlayer(config.SoundConfig{Enabled: true, Speech: speech}, WithOutput(out))
+	defer func() { _ = player.Close() }()
+
+	if err := player.Speak(context.Background(), "hello", 0); err != nil || out.plays != 0 {
+		t.Errorf("expected nothing to play, got %d plays (err %v)", out.plays, err)
+	}
+}
+
+func TestValidateSpeech(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.SpeechConfig
+		wantErr bool
+	}{
+		{name: "Disabled", cfg: config.SpeechConfig{Command: []string{"no-such-speech-command"}}},
+		{name: "Found", cfg: config.SpeechConfig{Enabled: true, Command: []string{os.Args[0]}}},
+		{name: "Not found", cfg: config.SpeechConfig{Enabled: true, Command: []string{filepath.Join(t.TempDir(), "no-such-speech-command")}}, wantErr: true},
+		{name: "No command", cfg: config.SpeechConfig{Enabled: true}, wantErr: true},
+		{name: "Invalid timeout", cfg: config.SpeechConfig{Enabled: true, Command: []string{os.Args[0]}, Timeout: "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSpeech(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateSpeech() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
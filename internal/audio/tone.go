@@ -0,0 +1,201 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// TonePrefix marks a sound setting as a note pattern instead of a file,
+// e.g. "tone:C5:200ms, E5:200ms, G5:400ms".
+const TonePrefix = "tone:"
+
+// toneRate is the sample rate tones are rendered at.
+const toneRate beep.SampleRate = 44100
+
+// tonePeak is the peak amplitude of a note, leaving headroom for mixing.
+const tonePeak = 0.5
+
+// note is a pitch held for a duration. A zero frequency is a rest.
+type note struct {
+	freq float64
+	dur  time.Duration
+}
+
+// semitones maps note letters to semitones above C.
+var semitones = map[byte]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+
+// waveforms map a phase in [0, 1) to a sample in [-1, 1].
+var waveforms = map[string]func(phase float64) float64{
+	"sine": func(phase float64) float64 {
+		return math.Sin(2 * math.Pi * phase)
+	},
+	"triangle": func(phase float64) float64 {
+		return 1 - 4*math.Abs(phase-0.5)
+	},
+	"square": func(phase float64) float64 {
+		if phase < 0.5 {
+			return 1
+		}
+		return -1
+	},
+	"sawtooth": func(phase float64) float64 {
+		return 2*phase - 1
+	},
+}
+
+// noteEnvelope describes how a note starts, rings and ends.
+type noteEnvelope struct {
+	attack, release time.Duration
+	// decay is the time constant of the exponential decay relative to the
+	// note length; zero holds the note at full level
+	decay float64
+}
+
+// envelopes are the named note envelopes.
+var envelopes = map[string]noteEnvelope{
+	// chime rings out over the whole note like a bell
+	"chime": {attack: 5 * time.Millisecond, release: 5 * time.Millisecond, decay: 1.0 / 3},
+	// pluck dies away quickly like a plucked string
+	"pluck": {attack: 2 * time.Millisecond, release: 5 * time.Millisecond, decay: 1.0 / 10},
+	// flat holds the note like an organ
+	"flat": {attack: 10 * time.Millisecond, release: 10 * time.Millisecond},
+}
+
+// parseTone parses a pattern of comma separated NOTE:DURATION items. Notes
+// are written as a letter, an optional # or b and an octave ("C5", "F#4",
+// "Bb3"), as a frequency ("440Hz") or as "rest".
+func parseTone(pattern string) ([]note, error) {
+	var notes []note
+	for _, item := range strings.Split(pattern, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		pitch, length, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid tone %q: missing duration, expected e.g. %q", item, "C5:200ms")
+		}
+		freq, err := parsePitch(strings.TrimSpace(pitch))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tone %q: %w", item, err)
+		}
+		dur, err := time.ParseDuration(strings.TrimSpace(length))
+		if err != nil || dur <= 0 {
+			return nil, fmt.Errorf("invalid tone %q: invalid duration %q", item, length)
+		}
+		notes = append(notes, note{freq: freq, dur: dur})
+	}
+
+	if len(notes) == 0 {
+		return nil, fmt.Errorf("invalid tone %q: no notes", pattern)
+	}
+	return notes, nil
+}
+
+// parsePitch returns the frequency of a note name or frequency.
+func parsePitch(s string) (float64, error) {
+	if strings.EqualFold(s, "rest") {
+		return 0, nil
+	}
+	if hz, ok := strings.CutSuffix(strings.ToLower(s), "hz"); ok {
+		f, err := strconv.ParseFloat(hz, 64)
+		if err != nil || f <= 0 || f > float64(toneRate)/2 {
+			return 0, fmt.Errorf("invalid frequency %q", s)
+		}
+		return f, nil
+	}
+
+	if s == "" {
+		return 0, fmt.Errorf("missing note")
+	}
+	semitone, ok := semitones[strings.ToUpper(s)[0]]
+	if !ok {
+		return 0, fmt.Errorf("unknown note %q", s)
+	}
+	rest := s[1:]
+	switch {
+	case strings.HasPrefix(rest, "#"):
+		semitone++
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "b"):
+		semitone--
+		rest = rest[1:]
+	}
+	octave, err := strconv.Atoi(rest)
+	if err != nil || octave < 0 || octave > 8 {
+		return 0, fmt.Errorf("invalid octave in note %q", s)
+	}
+
+	// MIDI note 69 is A4 at 440 Hz
+	midi := 12*(octave+1) + semitone
+	return 440 * math.Pow(2, float64(midi-69)/12), nil
+}
+
+// renderTone synthesizes a pattern with the given waveform and envelope.
+func renderTone(pattern, waveform, envelope string) (*beep.Buffer, error) {
+	notes, err := parseTone(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if waveform == "" {
+		waveform = "sine"
+	}
+	wave, ok := waveforms[waveform]
+	if !ok {
+		return nil, fmt.Errorf("invalid waveform %q: must be sine, triangle, square or sawtooth", waveform)
+	}
+	if envelope == "" {
+		envelope = "chime"
+	}
+	env, ok := envelopes[envelope]
+	if !ok {
+		return nil, fmt.Errorf("invalid envelope %q: must be chime, pluck or flat", envelope)
+	}
+
+	buf := beep.NewBuffer(beep.Format{SampleRate: toneRate, NumChannels: 1, Precision: 2})
+	for _, n := range notes {
+		buf.Append(renderNote(n, wave, env))
+	}
+	return buf, nil
+}
+
+// renderNote returns a streamer of one note.
+func renderNote(n note, wave func(float64) float64, env noteEnvelope) beep.Streamer {
+	total := toneRate.N(n.dur)
+	attack := min(toneRate.N(env.attack), total/2)
+	release := min(toneRate.N(env.release), total/2)
+	decay := env.decay * float64(total)
+
+	pos := 0
+	return beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		if pos >= total {
+			return 0, false
+		}
+		count := min(len(samples), total-pos)
+		for i := range samples[:count] {
+			var v float64
+			if n.freq > 0 {
+				v = wave(math.Mod(float64(pos)*n.freq/float64(toneRate), 1))
+			}
+			g := tonePeak
+			if pos < attack {
+				g *= float64(pos) / float64(attack)
+			}
+			if left := total - pos - 1; left < release {
+				g *= float64(left) / float64(release)
+			}
+			if decay > 0 {
+				g *= math.Exp(-float64(pos) / decay)
+			}
+			samples[i] = [2]float64{v * g, v * g}
+			pos++
+		}
+		return count, true
+	})
+}
@@ -0,0 +1,202 @@
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// samples returns the left channel of a buffer.
+func samples(buf *beep.Buffer) []float64 {
+	s := buf.Streamer(0, buf.Len())
+	out := make([]float64, 0, buf.Len())
+	chunk := make([][2]float64, 512)
+	for {
+		n, ok := s.Stream(chunk)
+		for _, v := range chunk[:n] {
+			out = append(out, v[0])
+		}
+		if !ok {
+			return out
+		}
+	}
+}
+
+// crossings counts rising zero crossings.
+func crossings(samples []float64) int {
+	count := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] < 0 && samples[i] >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// peak returns the largest absolute sample.
+func peak(samples []float64) float64 {
+	p := 0.0
+	for _, v := range samples {
+		p = max(p, math.Abs(v))
+	}
+	return p
+}
+
+func TestParseTone(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []note
+		wantErr bool
+	}{
+		{pattern: "A4:100ms", want: []note{{freq: 440, dur: 100 * time.Millisecond}}},
+		{pattern: "C5:200ms, E5:200ms, G5:400ms", want: []note{
+			{freq: 523.25, dur: 200 * time.Millisecond},
+			{freq: 659.26, dur: 200 * time.Millisecond},
+			{freq: 783.99, dur: 400 * time.Millisecond},
+		}},
+		{pattern: "F#4:1s, Bb3:50ms, rest:20ms, 1000Hz:10ms", want: []note{
+			{freq: 369.99, dur: time.Second},
+			{freq: 233.08, dur: 50 * time.Millisecond},
+			{freq: 0, dur: 20 * time.Millisecond},
+			{freq: 1000, dur: 10 * time.Millisecond},
+		}},
+		{pattern: "H5:100ms", wantErr: true},
+		{pattern: "C5", wantErr: true},
+		{pattern: "C5:fast", wantErr: true},
+		{pattern: "C9:100ms", wantErr: true},
+		{pattern: "30000Hz:100ms", wantErr: true},
+		{pattern: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := parseTone(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d notes, got %d", len(tt.want), len(got))
+			}
+			for i := range got {
+				if math.Abs(got[i].freq-tt.want[i].freq) > 0.01 || got[i].dur != tt.want[i].dur {
+					t.Errorf("note %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTone_Waveforms(t *testing.T) {
+	for _, waveform := range []string{"sine", "triangle", "square", "sawtooth"} {
+		t.Run(waveform, func(t *testing.T) {
+			buf, err := renderTone("A4:100ms", waveform, "flat")
+			if err != nil {
+				t.Fatalf("renderTone() error = %v", err)
+			}
+			s := samples(buf)
+
+			if len(s) != 4410 {
+				t.Errorf("expected 4410 samples, got %d", len(s))
+			}
+			// 100ms of 440 Hz has 44 cycles
+			if c := crossings(s); c < 43 || c > 45 {
+				t.Errorf("expected 44 cycles, got %d", c)
+			}
+			if p := peak(s); p > tonePeak || p < tonePeak*0.95 {
+				t.Errorf("expected peak near %v, got %v", tonePeak, p)
+			}
+			if s[0] != 0 || s[len(s)-1] != 0 {
+				t.Errorf("expected the note to start and end silent, got %v and %v", s[0], s[len(s)-1])
+			}
+		})
+	}
+}
+
+func TestRenderTone_Envelopes(t *testing.T) {
+	// rms returns the loudness of a 10ms window starting at ms
+	rms := func(s []float64, ms int) float64 {
+		start, end := toneRate.N(time.Duration(ms)*time.Millisecond), toneRate.N(time.Duration(ms+10)*time.Millisecond)
+		sum := 0.0
+		for _, v := range s[start:end] {
+			sum += v * v
+		}
+		return math.Sqrt(sum / float64(end-start))
+	}
+
+	render := func(envelope string) []float64 {
+		buf, err := renderTone("A4:200ms", "sine", envelope)
+		if err != nil {
+			t.Fatalf("renderTone() error = %v", err)
+		}
+		return samples(buf)
+	}
+
+	flat, chime, pluck := render("flat"), render("chime"), render("pluck")
+	if a, b := rms(flat, 20), rms(flat, 170); math.Abs(a-b) > 0.01 {
+		t.Errorf("flat: expected a held note, got %.3f then %.3f", a, b)
+	}
+	if a, b := rms(chime, 20), rms(chime, 170); b > a/4 {
+		t.Errorf("chime: expected a ringing decay, got %.3f then %.3f", a, b)
+	}
+	if a, b := rms(chime, 100), rms(pluck, 100); b > a/10 {
+		t.Errorf("pluck: expected a faster decay than chime, got %.3f vs %.3f", b, a)
+	}
+}
+
+func TestRenderTone_Sequence(t *testing.T) {
+	buf, err := renderTone("C5:200ms, rest:100ms, G5:400ms", "", "")
+	if err != nil {
+		t.Fatalf("renderTone() error = %v", err)
+	}
+	s := samples(buf)
+
+	if got, want := len(s), toneRate.N(700*time.Millisecond); got != want {
+		t.Fatalf("expected %d samples, got %d", want, got)
+	}
+	first := s[:toneRate.N(200*time.Millisecond)]
+	rest := s[len(first) : len(first)+toneRate.N(100*time.Millisecond)]
+	last := s[len(first)+len(rest):]
+
+	if c := crossings(first); c < 103 || c > 106 {
+		t.Errorf("expected about 104 cycles of C5, got %d", c)
+	}
+	if p := peak(rest); p != 0 {
+		t.Errorf("expected silence during the rest, got peak %v", p)
+	}
+	if c := crossings(last); c < 312 || c > 315 {
+		t.Errorf("expected about 313 cycles of G5, got %d", c)
+	}
+}
+
+func TestRenderTone_Errors(t *testing.T) {
+	if _, err := renderTone("C5:100ms", "noise", ""); err == nil {
+		t.Error("expected error for unknown waveform")
+	}
+	if _, err := renderTone("C5:100ms", "", "swell"); err == nil {
+		t.Error("expected error for unknown envelope")
+	}
+}
+
+func TestPlayer_Tone(t *testing.T) {
+	player := NewPlayer(config.SoundConfig{Enabled: true, File: "bell.wav", Tone: "A4:100ms"})
+	defer func() { _ = player.Close() }()
+
+	if err := player.Validate(TonePrefix + "C5:50ms"); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if sound := player.nextSound(player.playlistFor("")); sound == nil || sound.Len() != 4410 {
+		t.Error("expected the configured tone to replace the sound file")
+	}
+	if sound := player.nextSound(player.playlistFor(TonePrefix + "C5:50ms")); sound == nil || sound.Len() != 2205 {
+		t.Error("expected a tone pattern to be accepted as a sound")
+	}
+
+	bad := NewPlayer(config.SoundConfig{Enabled: true, Tone: "C5:100ms", Waveform: "noise"})
+	defer func() { _ = bad.Close() }()
+	if err := bad.Validate(); err == nil {
+		t.Error("expected Validate to report the invalid waveform")
+	}
+}
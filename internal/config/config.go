@@ -6,16 +6,32 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/message"
 	"github.com/spf13/viper"
 )
 
 // Config represents the complete application configuration.
 type Config struct {
 	Reminder     ReminderConfig     `mapstructure:"reminder"`
+	Focus        FocusConfig        `mapstructure:"focus"`
 	Sound        SoundConfig        `mapstructure:"sound"`
 	Notification NotificationConfig `mapstructure:"notification"`
 	Logging      LoggingConfig      `mapstructure:"logging"`
 	Service      ServiceConfig      `mapstructure:"service"`
+	State        StateConfig        `mapstructure:"state"`
+
+	// Templates are the notification templates above, parsed by Load
+	Templates Templates `mapstructure:"-"`
+}
+
+// Templates are the parsed notification titles and messages of a
+// configuration. Empty texts have no template.
+type Templates struct {
+	Title   *message.Template
+	Message *message.Template
+	Focus   *message.Template
+	// Variants are the messages of the reminder variants, by index
+	Variants []*message.Template
 }
 
 // ReminderConfig holds settings for the reminder scheduler.
@@ -24,16 +40,155 @@ type ReminderConfig struct {
 	Interval string `mapstructure:"interval"`
 	// TriggerMinutes are specific minutes to trigger (e.g., [0, 30])
 	TriggerMinutes []int `mapstructure:"trigger_minutes"`
+	// Schedule is a human-readable schedule that overrides Interval and
+	// TriggerMinutes (e.g., "every 45 minutes between 9am and 6pm on weekdays")
+	Schedule string `mapstructure:"schedule"`
+	// BreakDuration is how long the break after each reminder lasts (e.g., "5m")
+	BreakDuration string `mapstructure:"break_duration"`
+	// Variants customize reminders by time of day; the first match wins
+	Variants []VariantConfig `mapstructure:"variants"`
+	// Escalation raises the volume of reminders that are not acknowledged
+	Escalation EscalationConfig `mapstructure:"escalation"`
+}
+
+// EscalationConfig raises the volume of each reminder that follows an
+// unacknowledged one, starting quietly and getting louder up to a maximum.
+type EscalationConfig struct {
+	// Enabled turns escalation on; it replaces the volume of regular reminders
+	Enabled bool `mapstructure:"enabled"`
+	// Start is the volume of the first reminder after an acknowledgement
+	Start float64 `mapstructure:"start"`
+	// Step is how much louder each unacknowledged reminder gets
+	Step float64 `mapstructure:"step"`
+	// Max is the loudest escalated volume
+	Max float64 `mapstructure:"max"`
+}
+
+// VariantConfig overrides the message and sound of reminders that
+// trigger within a time-of-day range.
+type VariantConfig struct {
+	// Name identifies the variant in logs and CLI output (e.g., "morning")
+	Name string `mapstructure:"name"`
+	// From is the inclusive start of the range (e.g., "09:00")
+	From string `mapstructure:"from"`
+	// To is the exclusive end of the range (e.g., "12:00"), may wrap past midnight
+	To string `mapstructure:"to"`
+	// Message replaces the notification message (empty keeps the default)
+	Message string `mapstructure:"message"`
+	// Sound replaces the sound file (empty keeps the default)
+	Sound string `mapstructure:"sound"`
+	// Volume replaces the sound volume (0 keeps the default)
+	Volume float64 `mapstructure:"volume"`
+}
+
+// FocusConfig holds settings for focus sessions started with the focus command.
+type FocusConfig struct {
+	// Message is the notification message when a session finishes
+	Message string `mapstructure:"message"`
+	// Sound is the sound file played when a session finishes (empty for the default)
+	Sound string `mapstructure:"sound"`
+	// Volume is the volume of that sound (0 for the default)
+	Volume float64 `mapstructure:"volume"`
+	// BreakDuration is how long regular reminders stay paused after a session (e.g., "15m")
+	BreakDuration string `mapstructure:"break_duration"`
 }
 
 // SoundConfig holds settings for audio playback.
 type SoundConfig struct {
 	// Enabled indicates whether sound notifications are enabled
 	Enabled bool `mapstructure:"enabled"`
-	// File is the path to a custom sound file (empty for embedded)
+	// File is the path to a custom sound file or a directory of sound files
+	// (empty for embedded)
 	File string `mapstructure:"file"`
-	// Volume is the playback volume (0.0 - 1.0)
+	// Files are more sound files or directories to rotate through
+	Files []string `mapstructure:"files"`
+	// Order is how the sounds are rotated (sequential, random or shuffle)
+	Order string `mapstructure:"order"`
+	// Tone is a note pattern played instead of a file (e.g., "C5:200ms, E5:200ms, G5:400ms");
+	// other sound settings also accept patterns written as "tone:C5:200ms"
+	Tone string `mapstructure:"tone"`
+	// Waveform is the waveform of tones (sine, triangle, square or sawtooth)
+	Waveform string `mapstructure:"waveform"`
+	// Envelope shapes each note of a tone (chime, pluck or flat)
+	Envelope string `mapstructure:"envelope"`
+	// Volume is the playback volume (0.0 - 1.0); each halving is 10 dB quieter
 	Volume float64 `mapstructure:"volume"`
+	// MaxVolume caps every sound, including per-reminder volumes and sounds
+	// chosen on the command line: playback never peaks above a full-scale
+	// sound at this volume (0 for no cap)
+	MaxVolume float64 `mapstructure:"max_volume"`
+	// FadeIn is how long the sound takes to reach full volume (e.g., "200ms")
+	FadeIn string `mapstructure:"fade_in"`
+	// FadeOut is how long the end of the sound takes to fade to silence
+	FadeOut string `mapstructure:"fade_out"`
+	// MaxDuration cuts sounds that play longer than this (e.g., "30s"; empty for no limit)
+	MaxDuration string `mapstructure:"max_duration"`
+	// Backend is where sounds are played: speaker, null (discard) or wav (render to OutputFile)
+	Backend string `mapstructure:"backend"`
+	// OutputFile is the file the wav backend writes each sound to
+	OutputFile string `mapstructure:"output_file"`
+	// Fallback alerts the user while audio is unavailable: none, bell (terminal bell)
+	// or notification (desktop alert)
+	Fallback string `mapstructure:"fallback"`
+	// Preflight is what happens at startup when a configured sound cannot be
+	// played: warn (log it and skip the sound) or fail (refuse to start)
+	Preflight string `mapstructure:"preflight"`
+	// Ambient is the soundtrack played during breaks
+	Ambient AmbientConfig `mapstructure:"ambient"`
+	// Speech reads reminder messages aloud
+	Speech SpeechConfig `mapstructure:"speech"`
+	// Routines are guided breaks that sound settings refer to as
+	// "routine:NAME"; they replace built-in routines of the same name
+	Routines []RoutineConfig `mapstructure:"routines"`
+}
+
+// SpeechConfig holds settings for reading reminder messages aloud with a
+// local text-to-speech command.
+type SpeechConfig struct {
+	// Enabled speaks the message of each reminder after its sound
+	Enabled bool `mapstructure:"enabled"`
+	// Command is the speech program and its arguments; it reads the text on
+	// stdin and writes a WAV, MP3, OGG Vorbis or FLAC file to stdout
+	Command []string `mapstructure:"command"`
+	// Timeout is how long the command may take (e.g., "10s")
+	Timeout string `mapstructure:"timeout"`
+}
+
+// RoutineConfig describes a guided break as a sequence of timed cues.
+type RoutineConfig struct {
+	// Name identifies the routine (e.g., "breathing")
+	Name string `mapstructure:"name"`
+	// Steps are played in order
+	Steps []RoutineStepConfig `mapstructure:"steps"`
+	// Repeat is how many times the steps are played (default 1)
+	Repeat int `mapstructure:"repeat"`
+	// End is a sound played once after the last repetition (empty for none)
+	End string `mapstructure:"end"`
+}
+
+// RoutineStepConfig is a single cue of a routine.
+type RoutineStepConfig struct {
+	// Name describes what to do during the step (e.g., "inhale")
+	Name string `mapstructure:"name"`
+	// Sound is the cue played at the start of the step: a sound file or a
+	// "tone:" pattern (empty for silence)
+	Sound string `mapstructure:"sound"`
+	// Duration is how long the step lasts (e.g., "4s"); the cue is cut or
+	// followed by silence to fit, and empty means the length of the cue
+	Duration string `mapstructure:"duration"`
+}
+
+// AmbientConfig holds settings for the soundtrack played during breaks.
+type AmbientConfig struct {
+	// Sound is a sound file or directory to loop, or generated noise
+	// ("noise:white", "noise:pink" or "noise:brown"); empty disables it
+	Sound string `mapstructure:"sound"`
+	// Volume is the soundtrack volume (0.0 - 1.0)
+	Volume float64 `mapstructure:"volume"`
+	// FadeIn is how long the soundtrack takes to reach full volume (e.g., "3s")
+	FadeIn string `mapstructure:"fade_in"`
+	// FadeOut is how long the soundtrack takes to fade out at the end of the break
+	FadeOut string `mapstructure:"fade_out"`
 }
 
 // NotificationConfig holds settings for desktop notifications.
@@ -44,6 +199,58 @@ type NotificationConfig struct {
 	Title string `mapstructure:"title"`
 	// Message is the notification message body
 	Message string `mapstructure:"message"`
+	// Urgency is low, normal or critical
+	Urgency string `mapstructure:"urgency"`
+	// Icon is an icon file or theme icon name, empty for the built-in app
+	// icon or "none" for the notification server's default
+	Icon string `mapstructure:"icon"`
+	// Expire is how long notifications stay on screen (e.g., "30s"), empty
+	// for the notification server's default
+	Expire string `mapstructure:"expire"`
+	// Category tells the notification server what kind of notification
+	// this is (e.g., "presence")
+	Category string `mapstructure:"category"`
+	// Sticky keeps notifications on screen until they are dismissed
+	Sticky bool `mapstructure:"sticky"`
+	// Sinks are where each reminder is sent, all at once; Desktop adds a
+	// desktop sink if none is listed
+	Sinks []SinkConfig `mapstructure:"sinks"`
+	// Timeout is how long each sink may take (e.g., "10s")
+	Timeout string `mapstructure:"timeout"`
+	// Snooze is how long the Snooze button of a dbus notification puts
+	// off the reminder (e.g., "5m")
+	Snooze string `mapstructure:"snooze"`
+	// Tips suggests something to do during each break
+	Tips TipsConfig `mapstructure:"tips"`
+}
+
+// TipsConfig holds settings for the suggestions added to reminders.
+type TipsConfig struct {
+	// Enabled adds a tip that fits the break to each reminder
+	Enabled bool `mapstructure:"enabled"`
+	// Builtin includes the built-in eye, posture and stretching tips
+	Builtin bool `mapstructure:"builtin"`
+	// Files are YAML or Markdown files with more tips
+	Files []string `mapstructure:"files"`
+	// Categories limits tips to these categories (empty for all)
+	Categories []string `mapstructure:"categories"`
+}
+
+// SinkConfig describes one destination for notifications.
+type SinkConfig struct {
+	// Type is desktop, dbus, terminal, webhook or exec
+	Type string `mapstructure:"type"`
+	// Name identifies the sink in logs (empty for the type)
+	Name string `mapstructure:"name"`
+	// URL is where the webhook sink posts reminders as JSON
+	URL string `mapstructure:"url"`
+	// Headers are extra HTTP headers for the webhook sink
+	Headers map[string]string `mapstructure:"headers"`
+	// Command is the program and arguments the exec sink runs, with the
+	// message on stdin
+	Command []string `mapstructure:"command"`
+	// Timeout overrides the notification timeout for this sink
+	Timeout string `mapstructure:"timeout"`
 }
 
 // LoggingConfig holds settings for application logging.
@@ -62,22 +269,70 @@ type ServiceConfig struct {
 	Description string `mapstructure:"description"`
 }
 
+// StateConfig holds settings for runtime state shared between CLI commands
+// and the running instance.
+type StateConfig struct {
+	// Dir is the state directory (empty for $HOME/.rest-time-reminder)
+	Dir string `mapstructure:"dir"`
+}
+
 // DefaultConfig returns the default configuration.
 func DefaultConfig() *Config {
 	return &Config{
 		Reminder: ReminderConfig{
 			Interval:       "30m",
 			TriggerMinutes: nil,
+			BreakDuration:  "5m",
+			Escalation: EscalationConfig{
+				Enabled: false,
+				Start:   0.3,
+				Step:    0.2,
+				Max:     1.0,
+			},
+		},
+		Focus: FocusConfig{
+			Message:       "Focus session finished! Take a longer break.",
+			Sound:         "",
+			BreakDuration: "15m",
 		},
 		Sound: SoundConfig{
-			Enabled: true,
-			File:    "",
-			Volume:  1.0,
+			Enabled:     true,
+			File:        "",
+			Order:       "sequential",
+			Tone:        "",
+			Waveform:    "sine",
+			Envelope:    "chime",
+			Volume:      1.0,
+			MaxVolume:   1.0,
+			FadeIn:      "",
+			FadeOut:     "",
+			MaxDuration: "",
+			Backend:     "speaker",
+			Fallback:    "none",
+			Preflight:   "warn",
+			Ambient: AmbientConfig{
+				Sound:   "",
+				Volume:  0.5,
+				FadeIn:  "3s",
+				FadeOut: "10s",
+			},
+			Speech: SpeechConfig{
+				Enabled: false,
+				Command: []string{"espeak-ng", "--stdin", "--stdout"},
+				Timeout: "10s",
+			},
 		},
 		Notification: NotificationConfig{
 			Desktop: false,
 			Title:   "Break Time!",
 			Message: "Time to take a short break and rest your eyes.",
+			Urgency: "normal",
+			Timeout: "10s",
+			Snooze:  "5m",
+			Tips: TipsConfig{
+				Enabled: false,
+				Builtin: true,
+			},
 		},
 		Logging: LoggingConfig{
 			Level: "info",
@@ -87,6 +342,9 @@ func DefaultConfig() *Config {
 			DisplayName: "Rest Time Reminder",
 			Description: "A background service that reminds you to take regular breaks",
 		},
+		State: StateConfig{
+			Dir: "",
+		},
 	}
 }
 
@@ -132,21 +390,96 @@ func Load(configFile string) (*Config, error) {
 	if err := v.Unmarshal(cfg); err != nil {
 		return nil, fmt.Errorf("error parsing configuration: %w", err)
 	}
+	templates, err := cfg.ParseTemplates()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Templates = templates
 
 	return cfg, nil
 }
 
+// ParseTemplates parses the notification titles and messages of c, so a
+// typo fails at startup rather than at the first reminder.
+func (c *Config) ParseTemplates() (Templates, error) {
+	parse := func(key, text string) (*message.Template, error) {
+		if text == "" {
+			return nil, nil
+		}
+		tmpl, err := message.Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s template: %w", key, err)
+		}
+		return tmpl, nil
+	}
+
+	var t Templates
+	var err error
+	if t.Title, err = parse("notification.title", c.Notification.Title); err != nil {
+		return Templates{}, err
+	}
+	if t.Message, err = parse("notification.message", c.Notification.Message); err != nil {
+		return Templates{}, err
+	}
+	if t.Focus, err = parse("focus.message", c.Focus.Message); err != nil {
+		return Templates{}, err
+	}
+	t.Variants = make([]*message.Template, len(c.Reminder.Variants))
+	for i, v := range c.Reminder.Variants {
+		if t.Variants[i], err = parse(fmt.Sprintf("reminder.variants[%d].message", i), v.Message); err != nil {
+			return Templates{}, err
+		}
+	}
+	return t, nil
+}
+
 // setDefaults sets default values in the viper instance.
 func setDefaults(v *viper.Viper) {
 	defaults := DefaultConfig()
 
 	v.SetDefault("reminder.interval", defaults.Reminder.Interval)
+	v.SetDefault("reminder.break_duration", defaults.Reminder.BreakDuration)
+	v.SetDefault("reminder.escalation.enabled", defaults.Reminder.Escalation.Enabled)
+	v.SetDefault("reminder.escalation.start", defaults.Reminder.Escalation.Start)
+	v.SetDefault("reminder.escalation.step", defaults.Reminder.Escalation.Step)
+	v.SetDefault("reminder.escalation.max", defaults.Reminder.Escalation.Max)
+	v.SetDefault("focus.message", defaults.Focus.Message)
+	v.SetDefault("focus.break_duration", defaults.Focus.BreakDuration)
 	v.SetDefault("sound.enabled", defaults.Sound.Enabled)
+	v.SetDefault("sound.order", defaults.Sound.Order)
+	v.SetDefault("sound.tone", defaults.Sound.Tone)
+	v.SetDefault("sound.waveform", defaults.Sound.Waveform)
+	v.SetDefault("sound.envelope", defaults.Sound.Envelope)
 	v.SetDefault("sound.volume", defaults.Sound.Volume)
+	v.SetDefault("sound.max_volume", defaults.Sound.MaxVolume)
+	v.SetDefault("sound.fade_in", defaults.Sound.FadeIn)
+	v.SetDefault("sound.fade_out", defaults.Sound.FadeOut)
+	v.SetDefault("sound.max_duration", defaults.Sound.MaxDuration)
+	v.SetDefault("sound.backend", defaults.Sound.Backend)
+	v.SetDefault("sound.output_file", defaults.Sound.OutputFile)
+	v.SetDefault("sound.fallback", defaults.Sound.Fallback)
+	v.SetDefault("sound.preflight", defaults.Sound.Preflight)
+	v.SetDefault("sound.ambient.sound", defaults.Sound.Ambient.Sound)
+	v.SetDefault("sound.ambient.volume", defaults.Sound.Ambient.Volume)
+	v.SetDefault("sound.ambient.fade_in", defaults.Sound.Ambient.FadeIn)
+	v.SetDefault("sound.ambient.fade_out", defaults.Sound.Ambient.FadeOut)
+	v.SetDefault("sound.speech.enabled", defaults.Sound.Speech.Enabled)
+	v.SetDefault("sound.speech.command", defaults.Sound.Speech.Command)
+	v.SetDefault("sound.speech.timeout", defaults.Sound.Speech.Timeout)
 	v.SetDefault("notification.desktop", defaults.Notification.Desktop)
 	v.SetDefault("notification.title", defaults.Notification.Title)
 	v.SetDefault("notification.message", defaults.Notification.Message)
+	v.SetDefault("notification.urgency", defaults.Notification.Urgency)
+	v.SetDefault("notification.icon", defaults.Notification.Icon)
+	v.SetDefault("notification.expire", defaults.Notification.Expire)
+	v.SetDefault("notification.category", defaults.Notification.Category)
+	v.SetDefault("notification.sticky", defaults.Notification.Sticky)
+	v.SetDefault("notification.timeout", defaults.Notification.Timeout)
+	v.SetDefault("notification.snooze", defaults.Notification.Snooze)
+	v.SetDefault("notification.tips.enabled", defaults.Notification.Tips.Enabled)
+	v.SetDefault("notification.tips.builtin", defaults.Notification.Tips.Builtin)
 	v.SetDefault("logging.level", defaults.Logging.Level)
 	v.SetDefault("service.display_name", defaults.Service.DisplayName)
 	v.SetDefault("service.description", defaults.Service.Description)
+	v.SetDefault("state.dir", defaults.State.Dir)
 }
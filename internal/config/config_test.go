@@ -53,3 +53,31 @@ sound:
 		t.Error("expected sound disabled")
 	}
 }
+
+func TestLoad_InvalidTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "Valid", content: "notification:\n  message: \"Break {{.Count}}, next at {{clock .Next}}\"\n"},
+		{name: "Unknown field", content: "notification:\n  title: \"{{.Reminde}}\"\n", wantErr: true},
+		{name: "Variant", content: "reminder:\n  variants:\n    - name: morning\n      message: \"{{.Count\"\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := t.TempDir() + "/config.yaml"
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (cfg.Templates.Title == nil || cfg.Templates.Message == nil) {
+				t.Error("expected the title and message templates to be parsed")
+			}
+		})
+	}
+}
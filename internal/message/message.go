@@ -0,0 +1,82 @@
+// Package message renders notification titles and messages written as Go
+// templates, e.g. "Break {{.Count}} today, next at {{clock .Next}}".
+package message
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Data is what a template can refer to.
+type Data struct {
+	// Time is when the reminder fires
+	Time time.Time
+	// Reminder is the name of the variant, "focus", or empty for the default
+	Reminder string
+	// Break is how long the break after the reminder lasts (zero for none)
+	Break time.Duration
+	// Count is how many scheduled reminders fired today, including this one
+	Count int
+	// Worked is the time since the end of the last scheduled break
+	Worked time.Duration
+	// Next is when the next reminder fires (zero if none is scheduled)
+	Next time.Time
+	// Tip is a suggestion for the break (empty if tips are off)
+	Tip string
+}
+
+// sample is used to check templates when they are parsed.
+var sample = Data{
+	Time:     time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+	Reminder: "morning",
+	Break:    5 * time.Minute,
+	Count:    3,
+	Worked:   25 * time.Minute,
+	Next:     time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+	Tip:      "Look out of the window for 20 seconds.",
+}
+
+// funcs are the functions available to templates.
+var funcs = template.FuncMap{
+	// clock formats a time as "15:04", or "" for the zero time
+	"clock": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("15:04")
+	},
+	// minutes returns a duration in whole minutes
+	"minutes": func(d time.Duration) int {
+		return int(d / time.Minute)
+	},
+}
+
+// Template is a parsed title or message.
+type Template struct {
+	tmpl *template.Template
+}
+
+// Parse parses text as a template. Unknown fields and functions are
+// reported here rather than when the template is first rendered.
+func Parse(text string) (*Template, error) {
+	tmpl, err := template.New("message").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	t := &Template{tmpl: tmpl}
+	if _, err := t.Render(sample); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render fills in the template with d.
+func (t *Template) Render(d Data) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, d); err != nil {
+		return "", fmt.Errorf("failed to render %q: %w", t.tmpl.Root.String(), err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,65 @@
+package message
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{name: "Plain text", text: "Time to take a short break."},
+		{name: "Fields", text: "{{.Reminder}} at {{clock .Time}}: {{minutes .Break}} min break, #{{.Count}}, worked {{minutes .Worked}} min, next {{clock .Next}}"},
+		{name: "Unknown field", text: "{{.Tiem}}", wantErr: true},
+		{name: "Unknown function", text: "{{hours .Worked}}", wantErr: true},
+		{name: "Syntax error", text: "{{.Count", wantErr: true},
+		{name: "Wrong argument", text: "{{clock .Count}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.text); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl, err := Parse("Break {{.Count}} ({{minutes .Break}} min) after {{minutes .Worked}} min of work.{{if not .Next.IsZero}} Next at {{clock .Next}}.{{end}}")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data Data
+		want string
+	}{
+		{
+			name: "Next scheduled",
+			data: Data{Count: 2, Break: 5 * time.Minute, Worked: 55*time.Minute + 30*time.Second, Next: time.Date(2024, 1, 1, 11, 30, 0, 0, time.UTC)},
+			want: "Break 2 (5 min) after 55 min of work. Next at 11:30.",
+		},
+		{
+			name: "Nothing scheduled",
+			data: Data{Count: 1, Break: 15 * time.Minute},
+			want: "Break 1 (15 min) after 0 min of work.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tmpl.Render(tt.data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// The freedesktop notification service.
+const (
+	dbusName      = "org.freedesktop.Notifications"
+	dbusPath      = dbus.ObjectPath("/org/freedesktop/Notifications")
+	dbusInterface = "org.freedesktop.Notifications"
+	dbusAppName   = "Rest Time Reminder"
+)
+
+// Keys of the notification actions. The default action is invoked by
+// clicking the notification itself.
+const (
+	actionDefault = "default"
+	actionSnooze  = "snooze"
+	actionSkip    = "skip"
+	actionDone    = "done"
+)
+
+// closedByUser is the NotificationClosed reason for a notification the
+// user dismissed.
+const closedByUser = 2
+
+// Actions handles the buttons of a reminder's notification.
+type Actions interface {
+	// Snooze delivers the reminder again after d.
+	Snooze(d time.Duration)
+	// Skip ends the break.
+	Skip()
+	// Acknowledge records that the user noticed the reminder.
+	Acknowledge()
+}
+
+// actionSink is a sink whose notifications have buttons.
+type actionSink interface {
+	handleActions(h Actions)
+}
+
+// dbusSink shows notifications through the freedesktop notification
+// service, with Snooze, Skip and Done buttons once an Actions handler is
+// set. Each reminder replaces the previous one's notification rather than
+// piling up. It connects on the first reminder and reconnects if the bus
+// goes away.
+type dbusSink struct {
+	connect func() (*dbus.Conn, error)
+	options desktopOptions
+
+	mu      sync.Mutex
+	conn    *dbus.Conn
+	actions Actions
+	// shown are the notifications sent, until they are closed
+	shown map[uint32]bool
+	// last is the notification of the previous reminder (zero if closed)
+	last uint32
+}
+
+// sessionBus connects to the session bus of the desktop.
+func sessionBus() (*dbus.Conn, error) {
+	return dbus.ConnectSessionBus()
+}
+
+// newDBusSink creates a sink that connects to the bus with connect.
+func newDBusSink(connect func() (*dbus.Conn, error), options desktopOptions) *dbusSink {
+	return &dbusSink{connect: connect, options: options, shown: make(map[uint32]bool)}
+}
+
+// handleActions implements actionSink.
+func (s *dbusSink) handleActions(h Actions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions = h
+}
+
+// Send implements Sink.
+func (s *dbusSink) Send(ctx context.Context, n Notification) error {
+	conn, err := s.connection()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	replaces := s.last
+	s.mu.Unlock()
+	icon := s.options.icon.path()
+
+	var id uint32
+	call := conn.Object(dbusName, dbusPath).CallWithContext(ctx, dbusInterface+".Notify", 0,
+		dbusAppName,      // app_name
+		replaces,         // replaces_id
+		icon,             // app_icon
+		n.Title,          // summary
+		n.Message,        // body
+		s.buttons(),      // actions
+		s.hints(),        // hints
+		s.options.expire, // expire_timeout
+	)
+	if err := call.Store(&id); err != nil {
+		return fmt.Errorf("failed to show notification: %w", err)
+	}
+
+	s.mu.Lock()
+	s.shown[id] = true
+	s.last = id
+	s.mu.Unlock()
+	return nil
+}
+
+// hints returns the urgency and category of a notification.
+func (s *dbusSink) hints() map[string]dbus.Variant {
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(s.options.urgency),
+	}
+	if s.options.category != "" {
+		hints["category"] = dbus.MakeVariant(s.options.category)
+	}
+	return hints
+}
+
+// buttons returns the actions of a notification as key and label pairs,
+// or none if nothing handles them.
+func (s *dbusSink) buttons() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.actions == nil {
+		return []string{}
+	}
+	// The default action has no button, so it needs no label
+	return []string{
+		actionDefault, "",
+		actionSnooze, fmt.Sprintf("Snooze %s", formatSnooze(s.options.snooze)),
+		actionSkip, "Skip break",
+		actionDone, "Done",
+	}
+}
+
+// connection returns the connection to the bus, connecting and listening
+// for signals first if needed.
+func (s *dbusSink) connection() (*dbus.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil && s.conn.Connected() {
+		return s.conn, nil
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the session bus: %w", err)
+	}
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(dbusPath),
+		dbus.WithMatchInterface(dbusInterface),
+	); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to listen for notification actions: %w", err)
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go s.listen(signals)
+
+	s.conn = conn
+	clear(s.shown)
+	s.last = 0
+	return conn, nil
+}
+
+// listen routes the signals about our notifications to the Actions
+// handler until the connection is closed.
+func (s *dbusSink) listen(signals <-chan *dbus.Signal) {
+	for sig := range signals {
+		switch sig.Name {
+		case dbusInterface + ".ActionInvoked":
+			var id uint32
+			var key string
+			if err := dbus.Store(sig.Body, &id, &key); err != nil {
+				slog.Warn("malformed notification action", "error", err)
+				continue
+			}
+			// The notification closes after an action, so forget it now
+			// to not take that as a dismissal
+			if s.forget(id) {
+				s.invoke(key)
+			}
+		case dbusInterface + ".NotificationClosed":
+			var id, reason uint32
+			if err := dbus.Store(sig.Body, &id, &reason); err != nil {
+				slog.Warn("malformed notification closed signal", "error", err)
+				continue
+			}
+			if s.forget(id) && reason == closedByUser {
+				s.invoke(actionDone)
+			}
+		}
+	}
+	slog.Debug("stopped listening for notification actions")
+}
+
+// forget removes a notification from the shown ones, reporting whether
+// it was ours.
+func (s *dbusSink) forget(id uint32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.shown[id] {
+		return false
+	}
+	delete(s.shown, id)
+	if id == s.last {
+		s.last = 0
+	}
+	return true
+}
+
+// invoke runs the action with the given key.
+func (s *dbusSink) invoke(key string) {
+	s.mu.Lock()
+	h := s.actions
+	s.mu.Unlock()
+	if h == nil {
+		return
+	}
+
+	slog.Debug("notification action", "action", key)
+	switch key {
+	case actionSnooze:
+		h.Snooze(s.options.snooze)
+	case actionSkip:
+		h.Skip()
+	case actionDone, actionDefault:
+		h.Acknowledge()
+	default:
+		slog.Warn("unknown notification action", "action", key)
+	}
+}
+
+// formatSnooze formats a snooze duration for a button label, e.g. "5 min".
+func formatSnooze(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%d min", d/time.Minute)
+	}
+	return d.String()
+}
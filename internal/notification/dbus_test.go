@@ -0,0 +1,281 @@
+package notification
+
+import (
+	"bufio"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// startBus starts a private session bus and returns its address.
+func startBus(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read bus address: %v", err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+// fakeNotification is a notification as received by fakeServer.
+type fakeNotification struct {
+	id       uint32
+	replaces uint32
+	summary  string
+	body     string
+	actions  []string
+	hints    map[string]dbus.Variant
+	expire   int32
+}
+
+// fakeServer is a notification server that records what it is sent.
+type fakeServer struct {
+	conn  *dbus.Conn
+	mu    sync.Mutex
+	shown []fakeNotification
+}
+
+// newFakeServer registers a notification server on the bus at addr.
+func newFakeServer(t *testing.T, addr string) *fakeServer {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	f := &fakeServer{conn: conn}
+	if err := conn.Export(f, dbusPath, dbusInterface); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.RequestName(dbusName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("failed to own %s: %v (reply %v)", dbusName, err, reply)
+	}
+	return f
+}
+
+// Notify implements org.freedesktop.Notifications.Notify.
+func (f *fakeServer) Notify(_ string, replaces uint32, _, summary, body string, actions []string, hints map[string]dbus.Variant, expire int32) (uint32, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := replaces
+	if id == 0 {
+		id = uint32(len(f.shown) + 1)
+	}
+	f.shown = append(f.shown, fakeNotification{
+		id: id, replaces: replaces, summary: summary, body: body,
+		actions: actions, hints: hints, expire: expire,
+	})
+	return id, nil
+}
+
+// last returns the last notification received.
+func (f *fakeServer) last(t *testing.T) fakeNotification {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.shown) == 0 {
+		t.Fatal("no notification received")
+	}
+	return f.shown[len(f.shown)-1]
+}
+
+// emit sends a signal of the notification interface.
+func (f *fakeServer) emit(t *testing.T, name string, values ...any) {
+	t.Helper()
+	if err := f.conn.Emit(dbusPath, dbusInterface+"."+name, values...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeActions records the actions it is asked to take.
+type fakeActions chan string
+
+func (a fakeActions) Snooze(d time.Duration) { a <- "snooze " + d.String() }
+func (a fakeActions) Skip()                  { a <- "skip" }
+func (a fakeActions) Acknowledge()           { a <- "ack" }
+
+// expect waits for the next action and checks it is want.
+func (a fakeActions) expect(t *testing.T, want string) {
+	t.Helper()
+	select {
+	case got := <-a:
+		if got != want {
+			t.Errorf("expected action %q, got %q", want, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for action %q", want)
+	}
+}
+
+// waitForgotten waits until the sink handled the closing of notification id.
+func waitForgotten(t *testing.T, s *dbusSink, id uint32) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		s.mu.Lock()
+		shown := s.shown[id]
+		s.mu.Unlock()
+		if !shown {
+			return
+		}
+	}
+	t.Fatalf("notification %d was not forgotten", id)
+}
+
+func TestDBusSink_Actions(t *testing.T) {
+	addr := startBus(t)
+	server := newFakeServer(t, addr)
+
+	options := desktopOptions{expire: -1, snooze: 10 * time.Minute}
+	sink := newDBusSink(func() (*dbus.Conn, error) { return dbus.Connect(addr) }, options)
+	n := NewNotifier(config.NotificationConfig{Icon: iconNone}, WithSink("dbus", sink, 5*time.Second))
+	actions := make(fakeActions, 4)
+	n.HandleActions(actions)
+
+	notify := func() uint32 {
+		t.Helper()
+		if err := n.Notify("Break Time!", "Rest your eyes"); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+		return server.last(t).id
+	}
+
+	id := notify()
+	got := server.last(t)
+	if got.summary != "Break Time!" || got.body != "Rest your eyes" {
+		t.Errorf("expected the reminder, got %q: %q", got.summary, got.body)
+	}
+	for _, key := range []string{actionSnooze, "Snooze 10 min", actionSkip, actionDone} {
+		if !slices.Contains(got.actions, key) {
+			t.Errorf("expected action %q in %v", key, got.actions)
+		}
+	}
+	// Only the Done button is labelled Done, not clicking the notification
+	if i := slices.Index(got.actions, actionDefault); i < 0 || got.actions[i+1] != "" {
+		t.Errorf("expected an unlabelled default action in %v", got.actions)
+	}
+
+	// The notification closing after a button is not a dismissal
+	server.emit(t, "ActionInvoked", id, actionSnooze)
+	server.emit(t, "NotificationClosed", id, uint32(closedByUser))
+	actions.expect(t, "snooze 10m0s")
+
+	// Notifications of other programs and expired ones are ignored
+	id = notify()
+	server.emit(t, "ActionInvoked", uint32(999), actionDone)
+	server.emit(t, "NotificationClosed", id, uint32(1))
+	waitForgotten(t, sink, id)
+	id = notify()
+	server.emit(t, "ActionInvoked", id, actionSkip)
+	actions.expect(t, "skip")
+
+	// Dismissing a notification acknowledges it
+	id = notify()
+	server.emit(t, "NotificationClosed", id, uint32(closedByUser))
+	actions.expect(t, "ack")
+
+	id = notify()
+	server.emit(t, "ActionInvoked", id, actionDefault)
+	actions.expect(t, "ack")
+
+	select {
+	case a := <-actions:
+		t.Errorf("unexpected action %q", a)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDBusSink_NoActions(t *testing.T) {
+	addr := startBus(t)
+	server := newFakeServer(t, addr)
+
+	// Without a handler, notifications have no buttons
+	sink := newDBusSink(func() (*dbus.Conn, error) { return dbus.Connect(addr) }, desktopOptions{})
+	if err := sink.Send(t.Context(), testNote); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := server.last(t); len(got.actions) != 0 {
+		t.Errorf("expected no actions, got %v", got.actions)
+	}
+}
+
+func TestDBusSink_Replace(t *testing.T) {
+	addr := startBus(t)
+	server := newFakeServer(t, addr)
+
+	cfg := config.NotificationConfig{Urgency: "critical", Category: "presence", Sticky: true}
+	options, errs := parseDesktopOptions(cfg)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	sink := newDBusSink(func() (*dbus.Conn, error) { return dbus.Connect(addr) }, options)
+	sink.handleActions(make(fakeActions, 1))
+
+	send := func() fakeNotification {
+		t.Helper()
+		if err := sink.Send(t.Context(), testNote); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		return server.last(t)
+	}
+
+	first := send()
+	if first.replaces != 0 {
+		t.Errorf("expected a new notification, got one replacing %d", first.replaces)
+	}
+	if first.expire != 0 {
+		t.Errorf("expected a sticky notification, got expire %d", first.expire)
+	}
+	if u, ok := first.hints["urgency"].Value().(byte); !ok || u != 2 {
+		t.Errorf("expected critical urgency, got %v", first.hints["urgency"])
+	}
+	if c, _ := first.hints["category"].Value().(string); c != "presence" {
+		t.Errorf("expected category presence, got %v", first.hints["category"])
+	}
+
+	// The next reminder replaces the previous one instead of stacking up
+	if second := send(); second.replaces != first.id {
+		t.Errorf("expected notification %d to be replaced, got %d", first.id, second.replaces)
+	}
+
+	// Once it is closed, the next reminder gets a new notification
+	server.emit(t, "NotificationClosed", first.id, uint32(1))
+	waitForgotten(t, sink, first.id)
+	if third := send(); third.replaces != 0 {
+		t.Errorf("expected a new notification after the last was closed, got one replacing %d", third.replaces)
+	}
+}
+
+func TestDBusSink_NoServer(t *testing.T) {
+	addr := startBus(t)
+
+	sink := newDBusSink(func() (*dbus.Conn, error) { return dbus.Connect(addr) }, desktopOptions{})
+	if err := sink.Send(t.Context(), testNote); err == nil {
+		t.Error("expected an error without a notification server")
+	}
+}
@@ -0,0 +1,159 @@
+package notification
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// appIcon is the built-in icon of desktop notifications.
+//
+//go:embed icon.png
+var appIcon []byte
+
+// iconNone is the icon setting that leaves the icon to the notification
+// server.
+const iconNone = "none"
+
+// Urgency levels of the freedesktop notification specification.
+var urgencies = map[string]byte{
+	"low":      0,
+	"normal":   1,
+	"critical": 2,
+}
+
+// desktopOptions are the settings of desktop notifications.
+type desktopOptions struct {
+	urgency byte
+	// icon is the icon to show, nil for the server's default
+	icon     *icon
+	category string
+	// expire is in milliseconds, -1 for the server default or 0 for never
+	expire int32
+	// snooze is how long the Snooze button puts off a reminder
+	snooze time.Duration
+}
+
+// parseDesktopOptions reads the desktop notification settings of cfg.
+// Invalid settings fall back to their defaults and are reported in errs.
+func parseDesktopOptions(cfg config.NotificationConfig) (opts desktopOptions, errs []error) {
+	opts = desktopOptions{
+		urgency:  urgencies["normal"],
+		category: cfg.Category,
+		expire:   -1,
+		snooze:   defaultSnooze,
+	}
+
+	if cfg.Urgency != "" {
+		if u, ok := urgencies[cfg.Urgency]; ok {
+			opts.urgency = u
+		} else {
+			errs = append(errs, fmt.Errorf("invalid notification urgency %q: must be low, normal or critical", cfg.Urgency))
+		}
+	}
+
+	if cfg.Sticky {
+		opts.expire = 0
+	} else if cfg.Expire != "" {
+		if d, err := time.ParseDuration(cfg.Expire); err == nil && d > 0 {
+			opts.expire = int32(min(d.Milliseconds(), math.MaxInt32))
+		} else {
+			errs = append(errs, fmt.Errorf("invalid notification expire %q", cfg.Expire))
+		}
+	}
+
+	if cfg.Snooze != "" {
+		if d, err := time.ParseDuration(cfg.Snooze); err == nil && d > 0 {
+			opts.snooze = d
+		} else {
+			errs = append(errs, fmt.Errorf("invalid notification snooze %q", cfg.Snooze))
+		}
+	}
+	return opts, errs
+}
+
+// icon is the icon setting, resolved on the first notification so that
+// creating a Notifier writes nothing.
+type icon struct {
+	setting  string
+	once     sync.Once
+	resolved string
+}
+
+// newIcon returns the icon for the icon setting.
+func newIcon(setting string) *icon {
+	return &icon{setting: setting}
+}
+
+// path returns the file or theme icon to show, or "" for the server's
+// default, including when the built-in icon cannot be written.
+func (i *icon) path() string {
+	if i == nil {
+		return ""
+	}
+	i.once.Do(func() { i.resolved = resolveIcon(i.setting) })
+	return i.resolved
+}
+
+// resolveIcon returns the icon to show for the icon setting: the configured
+// file or theme icon, the built-in app icon, or "" for the server's default.
+func resolveIcon(icon string) string {
+	switch icon {
+	case "":
+		path, err := appIconPath()
+		if err != nil {
+			slog.Warn("failed to write app icon, using the default icon", "error", err)
+			return ""
+		}
+		return path
+	case iconNone:
+		return ""
+	}
+	return icon
+}
+
+// appIconPath writes the built-in app icon to the cache directory, unless
+// it is already there, since notification servers only take icons by file
+// name. It is written to a temporary file first, so another instance
+// never sees half of it.
+func appIconPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "rest-time-reminder", "icon.png")
+	if data, err := os.ReadFile(path); err == nil && bytes.Equal(data, appIcon) {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "icon-*.png")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	_, err = f.Write(appIcon)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
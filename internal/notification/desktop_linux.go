@@ -0,0 +1,10 @@
+//go:build linux
+
+package notification
+
+// newDesktopSink shows desktop notifications through the freedesktop
+// notification service, so every desktop option applies and reminders get
+// buttons, like the dbus sink.
+func newDesktopSink(options desktopOptions) Sink {
+	return newDBusSink(sessionBus, options)
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package notification
+
+import (
+	"context"
+
+	"github.com/gen2brain/beeep"
+)
+
+// newDesktopSink shows desktop notifications with beeep, which only
+// applies the icon of the desktop options.
+func newDesktopSink(options desktopOptions) Sink {
+	return desktopSink{options: options}
+}
+
+// desktopSink shows a desktop notification.
+type desktopSink struct {
+	options desktopOptions
+}
+
+// Send implements Sink.
+func (s desktopSink) Send(_ context.Context, n Notification) error {
+	// Empty string for icon will use system default
+	return beeep.Notify(n.Title, n.Message, s.options.icon.path())
+}
@@ -0,0 +1,125 @@
+package notification
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+func TestParseDesktopOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.NotificationConfig
+		want    desktopOptions
+		wantErr bool
+	}{
+		{
+			name: "Defaults",
+			cfg:  config.NotificationConfig{},
+			want: desktopOptions{urgency: 1, expire: -1, snooze: defaultSnooze},
+		},
+		{
+			name: "Configured",
+			cfg:  config.NotificationConfig{Urgency: "low", Icon: "/tmp/icon.png", Category: "presence", Expire: "30s", Snooze: "10m"},
+			want: desktopOptions{urgency: 0, category: "presence", expire: 30000, snooze: 10 * time.Minute},
+		},
+		{
+			name: "Sticky",
+			cfg:  config.NotificationConfig{Urgency: "critical", Expire: "30s", Sticky: true},
+			want: desktopOptions{urgency: 2, expire: 0, snooze: defaultSnooze},
+		},
+		{
+			name:    "Invalid urgency",
+			cfg:     config.NotificationConfig{Urgency: "urgent"},
+			want:    desktopOptions{urgency: 1, expire: -1, snooze: defaultSnooze},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid expire",
+			cfg:     config.NotificationConfig{Expire: "-5s"},
+			want:    desktopOptions{urgency: 1, expire: -1, snooze: defaultSnooze},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := parseDesktopOptions(tt.cfg)
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("parseDesktopOptions() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseDesktopOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveIcon(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if got := resolveIcon(iconNone); got != "" {
+		t.Errorf("expected the server's default icon, got %q", got)
+	}
+	if got := resolveIcon("alarm-clock"); got != "alarm-clock" {
+		t.Errorf("expected the configured icon, got %q", got)
+	}
+
+	// The built-in icon is written out for the notification server
+	path := resolveIcon("")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read app icon: %v", err)
+	}
+	if !bytes.Equal(data, appIcon) {
+		t.Error("expected the built-in app icon")
+	}
+
+	// A stale icon is replaced, leaving no temporary file behind
+	if err := os.WriteFile(path, []byte("old icon"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := resolveIcon(""); got != path {
+		t.Errorf("expected %q, got %q", path, got)
+	}
+	if data, err := os.ReadFile(path); err != nil || !bytes.Equal(data, appIcon) {
+		t.Errorf("expected the stale icon to be replaced, got error %v", err)
+	}
+	if entries, err := os.ReadDir(filepath.Dir(path)); err != nil || len(entries) != 1 {
+		t.Errorf("expected only the icon in its directory, got %v (error %v)", entries, err)
+	}
+}
+
+// setCacheDir points os.UserCacheDir into dir on every platform.
+func setCacheDir(t *testing.T, dir string) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LocalAppData", dir)
+}
+
+func TestNewNotifier_LazyIcon(t *testing.T) {
+	cache := t.TempDir()
+	setCacheDir(t, cache)
+
+	// Creating a notifier writes nothing, the first notification does
+	n := NewNotifier(config.NotificationConfig{Desktop: true})
+	if entries, err := os.ReadDir(cache); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no icon before the first notification, got %v (error %v)", entries, err)
+	}
+	path := n.desktop.icon.path()
+	if data, err := os.ReadFile(path); err != nil || !bytes.Equal(data, appIcon) {
+		t.Errorf("expected the built-in app icon at %q, got error %v", path, err)
+	}
+
+	// An icon that cannot be written is no icon
+	setCacheDir(t, filepath.Join(path, "not-a-dir"))
+	n = NewNotifier(config.NotificationConfig{Desktop: true})
+	if got := n.desktop.icon.path(); got != "" {
+		t.Errorf("expected the server's default icon, got %q", got)
+	}
+}
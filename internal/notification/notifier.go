@@ -1,51 +1,189 @@
-// Package notification provides desktop notification functionality.
+// Package notification sends reminders to the desktop and other sinks.
 package notification
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/gen2brain/beeep"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
 )
 
-// Notifier handles desktop notifications for the reminder.
+// defaultTimeout is how long a sink may take when no timeout is configured.
+const defaultTimeout = 10 * time.Second
+
+// defaultSnooze is how long a reminder is snoozed when no duration is
+// configured.
+const defaultSnooze = 5 * time.Minute
+
+// Notifier sends reminders to every configured sink.
 type Notifier struct {
 	config config.NotificationConfig
+	sinks  []namedSink
+	// desktop are the settings of desktop notifications and alerts
+	desktop desktopOptions
+	// errs are problems with the sink configuration, reported by Validate
+	errs []error
+	now  func() time.Time
 }
 
-// NewNotifier creates a new Notifier instance.
-func NewNotifier(cfg config.NotificationConfig) *Notifier {
-	return &Notifier{
+// namedSink is a sink with the name it is logged under and its timeout.
+type namedSink struct {
+	Sink
+	name    string
+	timeout time.Duration
+}
+
+// Option configures optional Notifier behavior.
+type Option func(*Notifier)
+
+// WithSink adds a sink to the configured ones.
+func WithSink(name string, s Sink, timeout time.Duration) Option {
+	return func(n *Notifier) {
+		n.sinks = append(n.sinks, namedSink{Sink: s, name: name, timeout: timeout})
+	}
+}
+
+// NewNotifier creates a new Notifier instance. Sinks that are not
+// configured correctly are left out and reported by Validate.
+func NewNotifier(cfg config.NotificationConfig, opts ...Option) *Notifier {
+	n := &Notifier{
 		config: cfg,
+		now:    time.Now,
 	}
+
+	timeout, err := parseTimeout(cfg.Timeout, defaultTimeout)
+	if err != nil {
+		n.errs = append(n.errs, fmt.Errorf("notification timeout: %w", err))
+	}
+	desktop, errs := parseDesktopOptions(cfg)
+	desktop.icon = newIcon(cfg.Icon)
+	n.desktop = desktop
+	n.errs = append(n.errs, errs...)
+
+	sinks := cfg.Sinks
+	if cfg.Desktop && !hasDesktop(sinks) {
+		sinks = append([]config.SinkConfig{{Type: sinkDesktop}}, sinks...)
+	}
+	for i, sc := range sinks {
+		name := sc.Name
+		if name == "" {
+			name = sc.Type
+		}
+		s, err := newSink(sc, desktop)
+		if err == nil {
+			var d time.Duration
+			if d, err = parseTimeout(sc.Timeout, timeout); err == nil {
+				n.sinks = append(n.sinks, namedSink{Sink: s, name: name, timeout: d})
+				continue
+			}
+		}
+		n.errs = append(n.errs, fmt.Errorf("notification sink %d (%s): %w", i+1, name, err))
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Validate reports sinks that were left out because of their configuration,
+// and settings that are invalid or have no effect.
+func (n *Notifier) Validate() error {
+	return errors.Join(n.errs...)
 }
 
-// Notify displays a desktop notification if enabled.
-func (n *Notifier) Notify() error {
-	if !n.config.Desktop {
-		slog.Debug("desktop notifications disabled, skipping")
+// HandleActions makes h handle the Snooze, Skip and Done buttons of
+// notifications, on the sinks that show them.
+func (n *Notifier) HandleActions(h Actions) {
+	for _, s := range n.sinks {
+		if as, ok := s.Sink.(actionSink); ok {
+			as.handleActions(h)
+		}
+	}
+}
+
+// Notify sends a reminder to every sink at once and waits for them to
+// finish or time out. An empty title or message falls back to the
+// configured one. A failing or hanging sink does not hold up the others;
+// the returned error lists every sink that failed.
+func (n *Notifier) Notify(title, message string) error {
+	if len(n.sinks) == 0 {
+		slog.Debug("notifications disabled, skipping")
 		return nil
 	}
 
-	slog.Debug("showing desktop notification",
-		"title", n.config.Title,
-		"message", n.config.Message,
+	if title == "" {
+		title = n.config.Title
+	}
+	if message == "" {
+		message = n.config.Message
+	}
+	note := Notification{Title: title, Message: message, Time: n.now()}
+
+	slog.Debug("sending notification",
+		"title", note.Title,
+		"message", note.Message,
+		"sinks", len(n.sinks),
 	)
 
-	// Show notification using beeep
-	// Empty string for icon will use system default
-	if err := beeep.Notify(n.config.Title, n.config.Message, ""); err != nil {
-		return fmt.Errorf("failed to show notification: %w", err)
+	errs := make([]error, len(n.sinks))
+	var wg sync.WaitGroup
+	for i, s := range n.sinks {
+		wg.Go(func() { errs[i] = send(s, note) })
 	}
+	wg.Wait()
 
-	return nil
+	return errors.Join(errs...)
+}
+
+// send delivers a notification to one sink. A sink that does not return
+// in time is abandoned, so it cannot block the reminder.
+func send(s namedSink, note Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- s.Send(ctx, note)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("sink %s: %w", s.name, err)
+		}
+		slog.Debug("notification sent", "sink", s.name)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("sink %s: timed out after %s", s.name, s.timeout)
+	}
 }
 
 // Alert displays an alert notification (more prominent than Notify).
 func (n *Notifier) Alert(title, message string) error {
-	if err := beeep.Alert(title, message, ""); err != nil {
+	if err := beeep.Alert(title, message, n.desktop.icon.path()); err != nil {
 		return fmt.Errorf("failed to show alert: %w", err)
 	}
 	return nil
 }
+
+// parseTimeout parses a sink timeout, returning def if it is empty.
+func parseTimeout(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return def, fmt.Errorf("invalid timeout %q", s)
+	}
+	return d, nil
+}
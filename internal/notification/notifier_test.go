@@ -1,12 +1,20 @@
 package notification
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
 )
 
 func TestNewNotifier(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
 	cfg := config.NotificationConfig{
 		Title:   "Test Title",
 		Message: "Test Message",
@@ -16,17 +24,19 @@ func TestNewNotifier(t *testing.T) {
 	if n == nil {
 		t.Fatal("NewNotifier returned nil")
 	}
-	if n.config != cfg {
+	if !reflect.DeepEqual(n.config, cfg) {
 		t.Errorf("expected config %+v, got %+v", cfg, n.config)
 	}
 }
 
 func TestNotifier_Notify_Disabled(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
 	cfg := config.NotificationConfig{
 		Desktop: false,
 	}
 	n := NewNotifier(cfg)
-	err := n.Notify()
+	err := n.Notify("", "")
 	if err != nil {
 		t.Errorf("expected nil error when disabled, got %v", err)
 	}
@@ -35,3 +45,132 @@ func TestNotifier_Notify_Disabled(t *testing.T) {
 // Note: Testing Notify() when enabled requires a desktop environment or mocking beeep,
 // which is not straightforward without refactoring.
 // Skipping "Enabled" test to avoid CI failure in headless environments.
+
+// fakeSink records notifications and behaves as configured.
+type fakeSink struct {
+	err   error
+	delay time.Duration
+	panic bool
+	sent  atomic.Int32
+	last  atomic.Value
+}
+
+func (s *fakeSink) Send(ctx context.Context, n Notification) error {
+	if s.panic {
+		panic("broken sink")
+	}
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			// Ignore cancellation like a sink stuck in a system call would
+			time.Sleep(s.delay)
+		}
+	}
+	s.sent.Add(1)
+	s.last.Store(n)
+	return s.err
+}
+
+func TestNotifier_Notify_FanOut(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	ok := &fakeSink{}
+	failing := &fakeSink{err: errors.New("server down")}
+	hanging := &fakeSink{delay: time.Minute}
+	panicking := &fakeSink{panic: true}
+
+	n := NewNotifier(config.NotificationConfig{Title: "Break Time!", Message: "Rest your eyes"},
+		WithSink("ok", ok, time.Second),
+		WithSink("failing", failing, time.Second),
+		WithSink("hanging", hanging, 100*time.Millisecond),
+		WithSink("panicking", panicking, time.Second),
+	)
+
+	start := time.Now()
+	err := n.Notify("", "")
+	if took := time.Since(start); took > 2*time.Second {
+		t.Errorf("expected Notify to give up on the hanging sink, took %v", took)
+	}
+
+	// Every working sink got the reminder, every broken one is reported
+	if ok.sent.Load() != 1 || failing.sent.Load() != 1 {
+		t.Errorf("expected the working sinks to be called once, got %d and %d", ok.sent.Load(), failing.sent.Load())
+	}
+	if got := ok.last.Load().(Notification); got.Title != "Break Time!" || got.Message != "Rest your eyes" {
+		t.Errorf("expected the configured title and message, got %+v", got)
+	}
+	for _, want := range []string{"sink failing: server down", "sink hanging: timed out", "sink panicking: panic: broken sink"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+	if err != nil && strings.Contains(err.Error(), "sink ok") {
+		t.Errorf("expected the working sink not to be reported, got %v", err)
+	}
+}
+
+func TestNewNotifier_Sinks(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tests := []struct {
+		name    string
+		cfg     config.NotificationConfig
+		sinks   []string
+		wantErr bool
+	}{
+		{name: "Disabled"},
+		{name: "Desktop shorthand", cfg: config.NotificationConfig{Desktop: true}, sinks: []string{"desktop"}},
+		{
+			name: "Desktop listed once",
+			cfg: config.NotificationConfig{Desktop: true, Sinks: []config.SinkConfig{
+				{Type: "terminal"}, {Type: "desktop", Name: "popup"},
+			}},
+			sinks: []string{"terminal", "popup"},
+		},
+		{
+			name: "Invalid sinks left out",
+			cfg: config.NotificationConfig{Sinks: []config.SinkConfig{
+				{Type: "pager"},
+				{Type: "webhook", URL: "ftp://example.com"},
+				{Type: "exec"},
+				{Type: "terminal", Timeout: "soon"},
+				{Type: "webhook", Name: "chat", URL: "https://example.com/hook", Timeout: "5s"},
+			}},
+			sinks:   []string{"chat"},
+			wantErr: true,
+		},
+		{name: "Invalid timeout", cfg: config.NotificationConfig{Timeout: "-1s"}, wantErr: true},
+		{
+			name:  "Desktop options",
+			cfg:   config.NotificationConfig{Desktop: true, Urgency: "critical", Sticky: true},
+			sinks: []string{"desktop"},
+		},
+		{
+			name:  "Options with dbus",
+			cfg:   config.NotificationConfig{Urgency: "critical", Sticky: true, Sinks: []config.SinkConfig{{Type: "dbus"}}},
+			sinks: []string{"dbus"},
+		},
+		{
+			name:  "Desktop shorthand with dbus",
+			cfg:   config.NotificationConfig{Desktop: true, Sinks: []config.SinkConfig{{Type: "dbus"}}},
+			sinks: []string{"dbus"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewNotifier(tt.cfg)
+			var names []string
+			for _, s := range n.sinks {
+				names = append(names, s.name)
+			}
+			if !reflect.DeepEqual(names, tt.sinks) {
+				t.Errorf("expected sinks %v, got %v", tt.sinks, names)
+			}
+			if err := n.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// Sink types understood by the notification.sinks setting.
+const (
+	sinkDesktop  = "desktop"
+	sinkTerminal = "terminal"
+	sinkWebhook  = "webhook"
+	sinkExec     = "exec"
+	sinkDBus     = "dbus"
+)
+
+// maxSinkOutput is how much of a failing command's or server's output is
+// kept for error messages.
+const maxSinkOutput = 512
+
+// Notification is a reminder as sent to sinks.
+type Notification struct {
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Sink delivers notifications to one destination.
+type Sink interface {
+	// Send delivers n, giving up when ctx is done.
+	Send(ctx context.Context, n Notification) error
+}
+
+// newSink creates the sink described by cfg. Desktop notifications are
+// shown with the given options.
+func newSink(cfg config.SinkConfig, desktop desktopOptions) (Sink, error) {
+	switch cfg.Type {
+	case sinkDesktop:
+		return newDesktopSink(desktop), nil
+	case sinkTerminal:
+		return &terminalSink{w: os.Stdout}, nil
+	case sinkWebhook:
+		u, err := url.Parse(cfg.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid webhook url %q", cfg.URL)
+		}
+		return &webhookSink{url: cfg.URL, headers: cfg.Headers, client: http.DefaultClient}, nil
+	case sinkExec:
+		if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+			return nil, errors.New("exec sink needs a command")
+		}
+		return &execSink{command: cfg.Command}, nil
+	case sinkDBus:
+		return newDBusSink(sessionBus, desktop), nil
+	}
+	return nil, fmt.Errorf("unknown sink type %q: must be desktop, dbus, terminal, webhook or exec", cfg.Type)
+}
+
+// hasDesktop reports whether a desktop or dbus sink is listed, either of
+// which already shows desktop notifications.
+func hasDesktop(sinks []config.SinkConfig) bool {
+	for _, s := range sinks {
+		if s.Type == sinkDesktop || s.Type == sinkDBus {
+			return true
+		}
+	}
+	return false
+}
+
+// terminalSink prints reminders, for running in a terminal or reading the
+// service log.
+type terminalSink struct {
+	w io.Writer
+}
+
+// Send implements Sink.
+func (s *terminalSink) Send(_ context.Context, n Notification) error {
+	_, err := fmt.Fprintf(s.w, "🔔 [%s] %s: %s\n", n.Time.Format("15:04"), n.Title, n.Message)
+	return err
+}
+
+// webhookSink posts reminders as JSON. The text field carries the title and
+// message together for chat services that only show that.
+type webhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// Send implements Sink.
+func (s *webhookSink) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(struct {
+		Notification
+		Text string `json:"text"`
+	}{n, n.Title + ": " + n.Message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxSinkOutput))
+		if text := strings.TrimSpace(string(msg)); text != "" {
+			return fmt.Errorf("webhook returned %s: %s", resp.Status, text)
+		}
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// execSink runs a command for each reminder, with the message on stdin and
+// the title and message in REMINDER_TITLE and REMINDER_MESSAGE.
+type execSink struct {
+	command []string
+}
+
+// Send implements Sink.
+func (s *execSink) Send(ctx context.Context, n Notification) error {
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...)
+	cmd.Stdin = strings.NewReader(n.Message)
+	cmd.Env = append(os.Environ(),
+		"REMINDER_TITLE="+n.Title,
+		"REMINDER_MESSAGE="+n.Message,
+		"REMINDER_TIME="+n.Time.Format(time.RFC3339),
+	)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if text := strings.TrimSpace(string(out[:min(len(out), maxSinkOutput)])); text != "" {
+			return fmt.Errorf("%w: %s", err, text)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+var testNote = Notification{
+	Title:   "Break Time!",
+	Message: "Rest your eyes",
+	Time:    time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+}
+
+func TestTerminalSink_Send(t *testing.T) {
+	var buf bytes.Buffer
+	s := &terminalSink{w: &buf}
+	if err := s.Send(context.Background(), testNote); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := "🔔 [10:30] Break Time!: Rest your eyes\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestWebhookSink_Send(t *testing.T) {
+	var got struct {
+		Notification
+		Text string `json:"text"`
+	}
+	var token string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		if strings.HasSuffix(r.URL.Path, "/broken") {
+			http.Error(w, "channel not found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := newSink(config.SinkConfig{Type: "webhook", URL: srv.URL + "/hook", Headers: map[string]string{"Authorization": "Bearer secret"}}, desktopOptions{})
+	if err != nil {
+		t.Fatalf("newSink() error = %v", err)
+	}
+	if err := s.Send(context.Background(), testNote); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.Title != testNote.Title || got.Message != testNote.Message || !got.Time.Equal(testNote.Time) {
+		t.Errorf("expected %+v, got %+v", testNote, got.Notification)
+	}
+	if got.Text != "Break Time!: Rest your eyes" || token != "Bearer secret" {
+		t.Errorf("expected text and header to be set, got %q and %q", got.Text, token)
+	}
+
+	broken, _ := newSink(config.SinkConfig{Type: "webhook", URL: srv.URL + "/broken"}, desktopOptions{})
+	err = broken.Send(context.Background(), testNote)
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "channel not found") {
+		t.Errorf("expected the status and body in the error, got %v", err)
+	}
+}
+
+func TestExecSink_Send(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	s, err := newSink(config.SinkConfig{
+		Type:    "exec",
+		Command: []string{"sh", "-c", `{ echo "$REMINDER_TITLE"; cat; } > "$0"`, out},
+	}, desktopOptions{})
+	if err != nil {
+		t.Fatalf("newSink() error = %v", err)
+	}
+	if err := s.Send(context.Background(), testNote); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Break Time!\nRest your eyes"; string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+
+	failing, _ := newSink(config.SinkConfig{Type: "exec", Command: []string{"sh", "-c", "echo no display >&2; exit 3"}}, desktopOptions{})
+	if err := failing.Send(context.Background(), testNote); err == nil || !strings.Contains(err.Error(), "no display") {
+		t.Errorf("expected the command output in the error, got %v", err)
+	}
+}
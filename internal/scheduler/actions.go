@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Acknowledge records that the user noticed the last reminder, so the
+// next one starts quiet again when escalating. With WithAcks it is
+// recorded like the ack command, so escalation reads it from one place.
+func (s *Scheduler) Acknowledge() {
+	slog.Info("✅ reminder acknowledged")
+	if s.acks != nil {
+		err := s.acks.Acknowledge(time.Now())
+		if err == nil {
+			return
+		}
+		slog.Error("failed to record acknowledgement", "error", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unacked = 0
+}
+
+// Skip acknowledges the last reminder and ends its sound and break.
+func (s *Scheduler) Skip() {
+	s.Acknowledge()
+	s.endBreak()
+
+	slog.Info("⏭️ break skipped")
+}
+
+// Snooze acknowledges the last reminder, ends its sound and break, and
+// delivers it again after d.
+func (s *Scheduler) Snooze(d time.Duration) {
+	s.Acknowledge()
+	s.endBreak()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastTrigger.Time.IsZero() {
+		slog.Warn("nothing to snooze")
+		return
+	}
+	s.snoozeUntil = time.Now().Add(d)
+	slog.Info("💤 reminder snoozed", "until", s.snoozeUntil.Format("15:04:05"))
+}
+
+// endBreak stops the sound and ambient soundtrack of the last reminder.
+// Work starts again now rather than at the planned end of the break.
+func (s *Scheduler) endBreak() {
+	s.player.Stop()
+	if s.ambience != nil && s.onBreak.Load() {
+		s.ambience.StopAmbient()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now := time.Now(); s.workStart.After(now) {
+		s.workStart = now
+	}
+}
+
+// fireSnoozed shows the snoozed reminder again once it is due, as it was
+// first rendered. Its break was ended by snoozing, so it gets no new one.
+func (s *Scheduler) fireSnoozed(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if s.snoozeUntil.IsZero() || now.Before(s.snoozeUntil) {
+		s.mu.Unlock()
+		return
+	}
+	tr, title, msg := s.lastTrigger, s.lastTitle, s.lastMessage
+	tr.Time = now
+	tr.Break = 0
+	s.snoozeUntil = time.Time{}
+	s.mu.Unlock()
+
+	slog.Info("💤 snoozed reminder fired", "variant", tr.Variant)
+	s.deliveries.Go(func() {
+		if ctx.Err() == nil {
+			s.remind(ctx, tr, title, msg)
+		}
+	})
+}
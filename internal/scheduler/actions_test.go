@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/state"
+)
+
+func TestScheduler_Acknowledge(t *testing.T) {
+	store, err := state.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "In memory"},
+		{name: "Recorded", opts: []Option{WithAcks(store)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := &MockPlayer{}
+			cfg := config.ReminderConfig{
+				Interval:   "30m",
+				Escalation: config.EscalationConfig{Enabled: true, Start: 0.3, Step: 0.2, Max: 0.8},
+			}
+			s := New(cfg, player, &MockNotifier{}, tt.opts...)
+			if err := s.prepare(); err != nil {
+				t.Fatalf("prepare() error = %v", err)
+			}
+
+			start := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+			s.trigger(context.Background(), start)
+			s.trigger(context.Background(), start.Add(30*time.Minute))
+			s.Acknowledge()
+			s.trigger(context.Background(), start.Add(time.Hour))
+			if math.Abs(player.LastVolume-0.3) > 1e-9 {
+				t.Errorf("expected escalation to start over, got volume %v", player.LastVolume)
+			}
+		})
+	}
+
+	// The button is recorded like the ack command
+	if at, err := store.LastAck(); err != nil || at.IsZero() {
+		t.Errorf("expected the acknowledgement to be recorded, got %v (err %v)", at, err)
+	}
+}
+
+func TestScheduler_Skip(t *testing.T) {
+	ambience := &MockAmbience{started: make(chan time.Duration, 1), stopped: make(chan struct{})}
+	s := New(config.ReminderConfig{Interval: "30m", BreakDuration: "5m"}, &MockPlayer{}, &MockNotifier{},
+		WithAmbience(nil, ambience),
+	)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.deliver(context.Background(), s.triggerAt(time.Now()))
+	}()
+	select {
+	case <-ambience.started:
+	case <-time.After(time.Second):
+		t.Fatal("ambient sound did not start")
+	}
+
+	s.Skip()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("break was not ended")
+	}
+	if s.workStart.After(time.Now()) {
+		t.Errorf("expected work to start again now, got %v", s.workStart)
+	}
+}
+
+func TestScheduler_Snooze(t *testing.T) {
+	notifier := &MockNotifier{}
+	s := New(config.ReminderConfig{Interval: "30m"}, &MockPlayer{}, notifier,
+		withNotification(t, config.NotificationConfig{Title: "Break #{{.Count}}"}),
+	)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Nothing was delivered yet, so there is nothing to snooze
+	s.Snooze(time.Minute)
+	s.fireSnoozed(ctx, time.Now().Add(time.Hour))
+	s.deliveries.Wait()
+	if notifier.NotifyCount != 0 {
+		t.Fatalf("expected no reminder, got %d", notifier.NotifyCount)
+	}
+
+	s.deliver(ctx, Trigger{Time: time.Now(), Message: "check the oven"})
+	s.Snooze(10 * time.Minute)
+
+	s.fireSnoozed(ctx, time.Now())
+	s.deliveries.Wait()
+	if notifier.NotifyCount != 1 {
+		t.Fatalf("expected the snoozed reminder to wait, got %d notifications", notifier.NotifyCount)
+	}
+
+	later := time.Now().Add(11 * time.Minute)
+	s.fireSnoozed(ctx, later)
+	s.fireSnoozed(ctx, later.Add(time.Second))
+	s.deliveries.Wait()
+	if notifier.NotifyCount != 2 || notifier.LastMessage != "check the oven" {
+		t.Errorf("expected the reminder once more, got %d notifications, last %q", notifier.NotifyCount, notifier.LastMessage)
+	}
+	// It is shown as rendered the first time, not counted again
+	if notifier.LastTitle != "Break #1" {
+		t.Errorf("expected the snoozed reminder's title, got %q", notifier.LastTitle)
+	}
+}
+
+func TestScheduler_Snooze_NoBreak(t *testing.T) {
+	notifier := &MockNotifier{}
+	tips := &MockTips{}
+	ambience := &MockAmbience{started: make(chan time.Duration, 1), stopped: make(chan struct{})}
+	s := New(config.ReminderConfig{Interval: "30m", BreakDuration: "5m"}, &MockPlayer{}, notifier,
+		WithAmbience(nil, ambience),
+		WithTips(tips),
+	)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.deliver(ctx, s.triggerAt(time.Now()))
+	}()
+	select {
+	case <-ambience.started:
+	case <-time.After(time.Second):
+		t.Fatal("ambient sound did not start")
+	}
+	s.Snooze(time.Minute)
+	<-done
+
+	// The reminder shows again, but its break does not start over
+	s.fireSnoozed(ctx, time.Now().Add(2*time.Minute))
+	s.deliveries.Wait()
+	if notifier.NotifyCount != 2 {
+		t.Errorf("expected the reminder once more, got %d notifications", notifier.NotifyCount)
+	}
+	if len(ambience.started) != 0 {
+		t.Error("expected no ambient sound for the snoozed reminder")
+	}
+	if len(tips.breaks) != 1 {
+		t.Errorf("expected one tip, got %d", len(tips.breaks))
+	}
+}
@@ -0,0 +1,237 @@
+package scheduler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// weekdays is the set of Monday through Friday.
+const weekdays weekdaySet = 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday
+
+// weekends is the set of Saturday and Sunday.
+const weekends weekdaySet = 1<<time.Saturday | 1<<time.Sunday
+
+// everyDay is the set of all days of the week.
+const everyDay = weekdays | weekends
+
+// weekdaySet is a bit set of time.Weekday values.
+type weekdaySet uint8
+
+// has reports whether d is in the set.
+func (w weekdaySet) has(d time.Weekday) bool {
+	return w&(1<<d) != 0
+}
+
+// period is a named time-of-day range usable in schedules.
+type period struct {
+	name  string
+	clock clockRange
+}
+
+// periods are the named ranges understood by schedules, e.g. "except at lunch".
+var periods = []period{
+	{name: "morning", clock: clockRange{start: 6 * 60, end: 12 * 60}},
+	{name: "lunch", clock: clockRange{start: 12 * 60, end: 13 * 60}},
+	{name: "afternoon", clock: clockRange{start: 13 * 60, end: 17 * 60}},
+	{name: "evening", clock: clockRange{start: 17 * 60, end: 21 * 60}},
+	{name: "night", clock: clockRange{start: 21 * 60, end: 6 * 60}},
+}
+
+// Rule is the scheduler's model of when reminders fire. Exactly one of
+// every, minutes or times is set; the remaining fields narrow it down.
+type Rule struct {
+	// every fires at this step, counted from the start of window (or midnight)
+	every time.Duration
+	// minutes are minutes past every hour
+	minutes []int
+	// times are times of day in minutes since midnight
+	times []int
+	// window limits reminders to a time-of-day range
+	window *clockRange
+	// days limits reminders to days of the week
+	days weekdaySet
+	// except excludes time-of-day ranges
+	except []clockRange
+}
+
+// ruleFromConfig builds the rule for a reminder configuration. The
+// schedule string takes precedence over trigger minutes and the interval.
+func ruleFromConfig(cfg config.ReminderConfig) (Rule, error) {
+	if cfg.Schedule != "" {
+		rule, err := ParseSchedule(cfg.Schedule)
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid schedule: %w", err)
+		}
+		return rule, nil
+	}
+
+	// If specific trigger minutes are configured, fire at them
+	if len(cfg.TriggerMinutes) > 0 {
+		for _, m := range cfg.TriggerMinutes {
+			if m < 0 || m > 59 {
+				return Rule{}, fmt.Errorf("invalid trigger minute %d: must be between 0 and 59", m)
+			}
+		}
+		return Rule{minutes: slices.Clone(cfg.TriggerMinutes), days: everyDay}, nil
+	}
+
+	// Otherwise, fire on the minutes of the hour that align with the interval
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid interval format: %w", err)
+	}
+	intervalMinutes := int(interval.Minutes())
+	if intervalMinutes <= 0 {
+		intervalMinutes = 30 // Default to 30 minutes
+	}
+	var minutes []int
+	for m := 0; m < 60; m += intervalMinutes {
+		minutes = append(minutes, m)
+	}
+	return Rule{minutes: minutes, days: everyDay}, nil
+}
+
+// Matches reports whether the rule fires in the minute containing t.
+func (r Rule) Matches(t time.Time) bool {
+	if !r.days.has(t.Weekday()) {
+		return false
+	}
+
+	m := t.Hour()*60 + t.Minute()
+	if r.window != nil && !r.window.contains(m) {
+		return false
+	}
+	for _, ex := range r.except {
+		if ex.contains(m) {
+			return false
+		}
+	}
+
+	switch {
+	case r.every > 0:
+		start := 0
+		if r.window != nil {
+			start = r.window.start
+		}
+		offset := (m - start + minutesPerDay) % minutesPerDay
+		return offset%int(r.every.Minutes()) == 0
+	case len(r.minutes) > 0:
+		return slices.Contains(r.minutes, t.Minute())
+	default:
+		return slices.Contains(r.times, m)
+	}
+}
+
+// String renders the rule as an English schedule that ParseSchedule accepts.
+func (r Rule) String() string {
+	var parts []string
+
+	switch {
+	case r.every > 0:
+		parts = append(parts, "every "+formatEvery(r.every))
+	case len(r.minutes) > 0:
+		items := make([]string, len(r.minutes))
+		for i, m := range r.minutes {
+			items[i] = fmt.Sprintf(":%02d", m)
+		}
+		parts = append(parts, "at "+joinAnd(items))
+	default:
+		items := make([]string, len(r.times))
+		for i, m := range r.times {
+			items[i] = formatClock(m)
+		}
+		parts = append(parts, "at "+joinAnd(items))
+	}
+
+	if r.window != nil {
+		parts = append(parts, "between "+formatClock(r.window.start)+" and "+formatClock(r.window.end))
+	}
+	if r.days != everyDay {
+		parts = append(parts, "on "+formatDays(r.days))
+	}
+	for _, ex := range r.except {
+		parts = append(parts, "except "+formatExcept(ex))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatEvery renders a step such as "45 minutes", "hour" or "2 hours".
+func formatEvery(d time.Duration) string {
+	minutes := int(d.Minutes())
+	switch {
+	case minutes == 1:
+		return "minute"
+	case minutes == 60:
+		return "hour"
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d hours", minutes/60)
+	default:
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+}
+
+// formatClock renders minutes since midnight as "9am", "12:30pm" or "midnight".
+func formatClock(m int) string {
+	m %= minutesPerDay
+	switch m {
+	case 0:
+		return "midnight"
+	case 12 * 60:
+		return "noon"
+	}
+
+	hour, minute := m/60, m%60
+	suffix := "am"
+	if hour >= 12 {
+		suffix = "pm"
+	}
+	if hour = hour % 12; hour == 0 {
+		hour = 12
+	}
+	if minute == 0 {
+		return fmt.Sprintf("%d%s", hour, suffix)
+	}
+	return fmt.Sprintf("%d:%02d%s", hour, minute, suffix)
+}
+
+// formatDays renders a day set such as "weekdays" or "Monday and Friday".
+func formatDays(days weekdaySet) string {
+	switch days {
+	case weekdays:
+		return "weekdays"
+	case weekends:
+		return "weekends"
+	}
+
+	var names []string
+	// Start the week on Monday
+	for i := 1; i <= 7; i++ {
+		if d := time.Weekday(i % 7); days.has(d) {
+			names = append(names, d.String())
+		}
+	}
+	return joinAnd(names)
+}
+
+// formatExcept renders an excluded range, preferring a period name.
+func formatExcept(r clockRange) string {
+	for _, p := range periods {
+		if p.clock == r {
+			return "at " + p.name
+		}
+	}
+	return "between " + formatClock(r.start) + " and " + formatClock(r.end)
+}
+
+// joinAnd joins items as "a", "a and b" or "a, b and c".
+func joinAnd(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
@@ -0,0 +1,431 @@
+package scheduler
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ParseError reports an invalid schedule and the word that caused it.
+type ParseError struct {
+	// Input is the schedule being parsed
+	Input string
+	// Word is the offending word (empty when the schedule ended too early)
+	Word string
+	// Offset is the byte offset of Word in Input
+	Offset int
+	// Msg describes what was expected instead
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	if e.Word == "" {
+		return fmt.Sprintf("schedule %q: unexpected end of schedule: %s", e.Input, e.Msg)
+	}
+	return fmt.Sprintf("schedule %q: unexpected %q at column %d: %s", e.Input, e.Word, e.Offset+1, e.Msg)
+}
+
+// token is a lower-cased word of a schedule and its position in the input.
+type token struct {
+	text   string
+	offset int
+}
+
+// dayNames maps day words to the days they cover.
+var dayNames = map[string]weekdaySet{
+	"weekday": weekdays, "weekdays": weekdays,
+	"weekend": weekends, "weekends": weekends,
+	"daily": everyDay,
+}
+
+func init() {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		set := weekdaySet(1 << d)
+		dayNames[name] = set
+		dayNames[name+"s"] = set
+		dayNames[name[:3]] = set
+	}
+}
+
+// parser is a recursive-descent parser over schedule words.
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+	rule   Rule
+	onDays bool
+}
+
+// ParseSchedule parses a human-readable schedule such as
+// "every 45 minutes between 9am and 6pm on weekdays" or
+// "at :00 and :30 except at lunch".
+//
+// A schedule has exactly one frequency clause ("every ..." or "at ...")
+// optionally followed by "between X and Y" (or "from X to Y"),
+// "on <days>" and any number of "except ..." clauses.
+func ParseSchedule(s string) (Rule, error) {
+	p := &parser{input: s, tokens: tokenize(s)}
+	p.rule.days = everyDay
+
+	if len(p.tokens) == 0 {
+		return Rule{}, p.errorf(token{}, "schedule is empty")
+	}
+
+	for p.pos < len(p.tokens) {
+		if err := p.clause(); err != nil {
+			return Rule{}, err
+		}
+	}
+
+	if p.rule.every == 0 && len(p.rule.minutes) == 0 && len(p.rule.times) == 0 {
+		return Rule{}, p.errorf(token{}, `missing frequency: add "every ..." or "at ..."`)
+	}
+	if p.rule.days == 0 {
+		return Rule{}, p.errorf(token{}, "schedule excludes every day of the week")
+	}
+	return p.rule, nil
+}
+
+// tokenize splits a schedule into lower-cased words, treating commas as spaces.
+func tokenize(s string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range s + " " {
+		if unicode.IsSpace(r) || r == ',' {
+			if start >= 0 {
+				tokens = append(tokens, token{text: strings.ToLower(s[start:i]), offset: start})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	return tokens
+}
+
+// peek returns the current token without consuming it, or an empty token at the end.
+func (p *parser) peek() token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return token{}
+}
+
+// next consumes and returns the current token, or an empty token at the end.
+func (p *parser) next() token {
+	tok := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return tok
+}
+
+// errorf returns a ParseError pointing at tok.
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return &ParseError{Input: p.input, Word: tok.text, Offset: tok.offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// clause parses one clause of the schedule.
+func (p *parser) clause() error {
+	tok := p.next()
+	switch tok.text {
+	case "every":
+		return p.parseEvery(tok)
+	case "at":
+		return p.parseAt(tok)
+	case "between":
+		return p.parseWindow(tok, "and")
+	case "from":
+		return p.parseWindow(tok, "to", "until")
+	case "on":
+		return p.parseOn(tok)
+	case "except":
+		return p.parseExcept()
+	}
+
+	if _, ok := dayNames[tok.text]; ok {
+		p.pos--
+		return p.parseOn(tok)
+	}
+	return p.errorf(tok, `expected "every", "at", "between", "from", "on" or "except"`)
+}
+
+// setFrequency rejects a second frequency clause.
+func (p *parser) setFrequency(tok token) error {
+	if p.rule.every > 0 || len(p.rule.minutes) > 0 || len(p.rule.times) > 0 {
+		return p.errorf(tok, `a schedule can only have one "every" or "at" clause`)
+	}
+	return nil
+}
+
+// parseEvery parses "every [N] minutes|hours" or "every <duration>".
+func (p *parser) parseEvery(every token) error {
+	if err := p.setFrequency(every); err != nil {
+		return err
+	}
+
+	tok := p.next()
+	count := 1
+	if n, err := strconv.Atoi(tok.text); err == nil {
+		if n <= 0 {
+			return p.errorf(tok, "expected a positive number")
+		}
+		count = n
+		tok = p.next()
+	} else if d, err := time.ParseDuration(tok.text); err == nil {
+		return p.setEvery(tok, d)
+	}
+
+	switch tok.text {
+	case "minute", "minutes", "min", "mins":
+		return p.setEvery(tok, time.Duration(count)*time.Minute)
+	case "hour", "hours":
+		return p.setEvery(tok, time.Duration(count)*time.Hour)
+	}
+	return p.errorf(tok, `expected "minutes" or "hours"`)
+}
+
+// setEvery validates and stores the step of an "every" clause.
+func (p *parser) setEvery(tok token, d time.Duration) error {
+	if d < time.Minute || d > 24*time.Hour || d%time.Minute != 0 {
+		return p.errorf(tok, "interval must be a whole number of minutes between 1 minute and 24 hours")
+	}
+	p.rule.every = d
+	return nil
+}
+
+// parseAt parses "at :00 and :30" or "at 9am, 1pm and 5:30pm".
+func (p *parser) parseAt(at token) error {
+	if err := p.setFrequency(at); err != nil {
+		return err
+	}
+
+	for {
+		tok := p.peek()
+		switch {
+		case strings.HasPrefix(tok.text, ":"):
+			p.next()
+			m, err := strconv.Atoi(tok.text[1:])
+			if err != nil || m < 0 || m > 59 {
+				return p.errorf(tok, "expected minutes past the hour such as :00 or :30")
+			}
+			if len(p.rule.times) > 0 {
+				return p.errorf(tok, "cannot mix minutes past the hour with times of day")
+			}
+			p.rule.minutes = append(p.rule.minutes, m)
+		case isTimeStart(tok.text):
+			m, err := p.parseTime()
+			if err != nil {
+				return err
+			}
+			if len(p.rule.minutes) > 0 {
+				return p.errorf(tok, "cannot mix minutes past the hour with times of day")
+			}
+			p.rule.times = append(p.rule.times, m)
+		default:
+			return p.errorf(tok, "expected a time such as :30, 9am or 14:30")
+		}
+
+		// Continue with "and <item>" or a comma-separated item
+		if p.peek().text == "and" && p.pos+1 < len(p.tokens) && isAtItem(p.tokens[p.pos+1].text) {
+			p.next()
+			continue
+		}
+		if !isAtItem(p.peek().text) {
+			break
+		}
+	}
+
+	slices.Sort(p.rule.minutes)
+	p.rule.minutes = slices.Compact(p.rule.minutes)
+	slices.Sort(p.rule.times)
+	p.rule.times = slices.Compact(p.rule.times)
+	return nil
+}
+
+// parseWindow parses "<time> and <time>" after "between", or "<time> to <time>" after "from".
+func (p *parser) parseWindow(tok token, separators ...string) error {
+	if p.rule.window != nil {
+		return p.errorf(tok, "a schedule can only have one time range")
+	}
+
+	r, err := p.parseRange(separators...)
+	if err != nil {
+		return err
+	}
+	p.rule.window = &r
+	return nil
+}
+
+// parseRange parses "<time> <separator> <time>".
+func (p *parser) parseRange(separators ...string) (clockRange, error) {
+	start, err := p.parseTime()
+	if err != nil {
+		return clockRange{}, err
+	}
+
+	sep := p.next()
+	if !slices.Contains(separators, sep.text) {
+		return clockRange{}, p.errorf(sep, "expected %q", strings.Join(separators, `" or "`))
+	}
+
+	endTok := p.peek()
+	end, err := p.parseTime()
+	if err != nil {
+		return clockRange{}, err
+	}
+	if start == end {
+		return clockRange{}, p.errorf(endTok, "time range is empty")
+	}
+	return clockRange{start: start, end: end}, nil
+}
+
+// parseOn parses a list of days such as "weekdays" or "monday, wednesday and friday".
+func (p *parser) parseOn(on token) error {
+	if p.onDays {
+		return p.errorf(on, "days are already set")
+	}
+
+	days, err := p.parseDays()
+	if err != nil {
+		return err
+	}
+	p.rule.days = days
+	p.onDays = true
+	return nil
+}
+
+// parseDays parses one or more day words joined by commas or "and".
+func (p *parser) parseDays() (weekdaySet, error) {
+	var days weekdaySet
+	for {
+		tok := p.next()
+		set, ok := dayNames[tok.text]
+		if !ok {
+			return 0, p.errorf(tok, `expected a day such as "monday", "weekdays" or "weekends"`)
+		}
+		days |= set
+
+		next := p.peek().text
+		if next == "and" && p.pos+1 < len(p.tokens) {
+			if _, ok := dayNames[p.tokens[p.pos+1].text]; ok {
+				p.next()
+				continue
+			}
+		}
+		if _, ok := dayNames[next]; !ok {
+			return days, nil
+		}
+	}
+}
+
+// parseExcept parses "except [at|during] <period>", "except between X and Y"
+// or "except [on] <days>".
+func (p *parser) parseExcept() error {
+	tok := p.next()
+	if tok.text == "at" || tok.text == "during" {
+		tok = p.next()
+	}
+
+	if tok.text == "between" {
+		r, err := p.parseRange("and")
+		if err != nil {
+			return err
+		}
+		p.rule.except = append(p.rule.except, r)
+		return nil
+	}
+
+	for _, period := range periods {
+		if tok.text == period.name {
+			p.rule.except = append(p.rule.except, period.clock)
+			return nil
+		}
+	}
+
+	if tok.text != "on" {
+		p.pos--
+	}
+	if _, ok := dayNames[p.peek().text]; ok {
+		days, err := p.parseDays()
+		if err != nil {
+			return err
+		}
+		p.rule.days &^= days
+		return nil
+	}
+
+	return p.errorf(tok, `expected a period (morning, lunch, afternoon, evening, night), "between" or days`)
+}
+
+// parseTime parses a time of day such as "9am", "9 am", "9:30pm", "14:30",
+// "noon" or "midnight" into minutes since midnight.
+func (p *parser) parseTime() (int, error) {
+	tok := p.next()
+	switch tok.text {
+	case "noon":
+		return 12 * 60, nil
+	case "midnight":
+		return 0, nil
+	case "":
+		return 0, p.errorf(tok, "expected a time such as 9am or 14:30")
+	}
+
+	text := tok.text
+	suffix := ""
+	switch {
+	case strings.HasSuffix(text, "am"), strings.HasSuffix(text, "pm"):
+		text, suffix = text[:len(text)-2], text[len(text)-2:]
+	case p.peek().text == "am", p.peek().text == "pm":
+		suffix = p.next().text
+	}
+
+	hourText, minuteText, hasMinutes := strings.Cut(text, ":")
+	hour, err := strconv.Atoi(hourText)
+	if err != nil {
+		return 0, p.errorf(tok, "expected a time such as 9am or 14:30")
+	}
+	minute := 0
+	if hasMinutes {
+		if minute, err = strconv.Atoi(minuteText); err != nil || len(minuteText) != 2 || minute > 59 {
+			return 0, p.errorf(tok, "expected minutes between :00 and :59")
+		}
+	}
+
+	switch suffix {
+	case "":
+		if !hasMinutes {
+			return 0, p.errorf(tok, `ambiguous time: add "am" or "pm", or use 24-hour HH:MM`)
+		}
+		if hour > 23 {
+			return 0, p.errorf(tok, "hour must be between 0 and 23")
+		}
+	default:
+		if hour < 1 || hour > 12 {
+			return 0, p.errorf(tok, "hour must be between 1 and 12 with am/pm")
+		}
+		hour %= 12
+		if suffix == "pm" {
+			hour += 12
+		}
+	}
+
+	return hour*60 + minute, nil
+}
+
+// isTimeStart reports whether a word can start a time of day.
+func isTimeStart(word string) bool {
+	return word == "noon" || word == "midnight" || (word != "" && unicode.IsDigit(rune(word[0])))
+}
+
+// isAtItem reports whether a word can be an item of an "at" clause.
+func isAtItem(word string) bool {
+	return strings.HasPrefix(word, ":") || isTimeStart(word)
+}
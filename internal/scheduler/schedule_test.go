@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// at returns a time on Monday 2023-01-02 (or the given weekday offset) at hh:mm.
+func at(dayOffset, hour, minute int) time.Time {
+	return time.Date(2023, 1, 2+dayOffset, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParseSchedule_Matches(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		match    []time.Time
+		noMatch  []time.Time
+	}{
+		{
+			name:     "Every 45 minutes in a weekday window",
+			schedule: "every 45 minutes between 9am and 6pm on weekdays",
+			match:    []time.Time{at(0, 9, 0), at(0, 9, 45), at(0, 10, 30), at(4, 17, 15)},
+			noMatch:  []time.Time{at(0, 8, 15), at(0, 10, 0), at(0, 18, 0), at(5, 9, 0)},
+		},
+		{
+			name:     "Minutes past the hour except at lunch",
+			schedule: "at :00 and :30 except at lunch",
+			match:    []time.Time{at(0, 11, 30), at(0, 13, 0), at(6, 3, 0)},
+			noMatch:  []time.Time{at(0, 12, 0), at(0, 12, 30), at(0, 13, 15)},
+		},
+		{
+			name:     "Times of day on selected days",
+			schedule: "at 9am, 1:30pm and 17:00 on Monday, Wednesday and Friday",
+			match:    []time.Time{at(0, 9, 0), at(2, 13, 30), at(4, 17, 0)},
+			noMatch:  []time.Time{at(1, 9, 0), at(0, 9, 30), at(0, 13, 0)},
+		},
+		{
+			name:     "Every hour from-to with separate am/pm words",
+			schedule: "every hour from 8 am to 5 pm",
+			match:    []time.Time{at(0, 8, 0), at(5, 16, 0)},
+			noMatch:  []time.Time{at(0, 7, 0), at(0, 17, 0), at(0, 8, 30)},
+		},
+		{
+			name:     "Duration literal except on weekends",
+			schedule: "every 90m except on weekends",
+			match:    []time.Time{at(0, 0, 0), at(0, 1, 30), at(0, 3, 0)},
+			noMatch:  []time.Time{at(0, 1, 0), at(5, 0, 0), at(6, 1, 30)},
+		},
+		{
+			name:     "Bare day words and custom exception range",
+			schedule: "weekends every 30 minutes except between noon and 2pm",
+			match:    []time.Time{at(5, 11, 30), at(6, 14, 0)},
+			noMatch:  []time.Time{at(0, 11, 30), at(5, 12, 0), at(5, 13, 30)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseSchedule(tt.schedule)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error = %v", err)
+			}
+			for _, tm := range tt.match {
+				if !rule.Matches(tm) {
+					t.Errorf("expected %s to match", tm.Format("Mon 15:04"))
+				}
+			}
+			for _, tm := range tt.noMatch {
+				if rule.Matches(tm) {
+					t.Errorf("expected %s not to match", tm.Format("Mon 15:04"))
+				}
+			}
+		})
+	}
+}
+
+func TestParseSchedule_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		word     string
+	}{
+		{name: "Misspelled unit", schedule: "every 45 minuts", word: "minuts"},
+		{name: "Unknown clause", schedule: "every hour sometimes", word: "sometimes"},
+		{name: "Ambiguous time", schedule: "every hour between 9 and 5pm", word: "9"},
+		{name: "Bad minute", schedule: "at :75", word: ":75"},
+		{name: "Unknown day", schedule: "every hour on caturday", word: "caturday"},
+		{name: "Unknown period", schedule: "at :00 except at teatime", word: "teatime"},
+		{name: "Mixed at items", schedule: "at :00 and 9am", word: "9am"},
+		{name: "Two frequencies", schedule: "every hour at :30", word: "at"},
+		{name: "Missing separator", schedule: "every hour between 9am 5pm", word: "5pm"},
+		{name: "Missing frequency", schedule: "on weekdays", word: ""},
+		{name: "Truncated", schedule: "every 45", word: ""},
+		{name: "Empty", schedule: "  ", word: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchedule(tt.schedule)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *ParseError, got %v", err)
+			}
+			if perr.Word != tt.word {
+				t.Errorf("error points at %q, want %q (%v)", perr.Word, tt.word, err)
+			}
+			if tt.word != "" && tt.schedule[perr.Offset:perr.Offset+len(tt.word)] != tt.word {
+				t.Errorf("offset %d does not point at %q", perr.Offset, tt.word)
+			}
+		})
+	}
+}
+
+func TestRule_String_RoundTrip(t *testing.T) {
+	tests := []struct {
+		schedule string
+		want     string
+	}{
+		{schedule: "every 45 minutes between 9am and 6pm on weekdays", want: "every 45 minutes between 9am and 6pm on weekdays"},
+		{schedule: "at :30, :00 except during lunch", want: "at :00 and :30 except at lunch"},
+		{schedule: "every 2 hours from 08:00 until 20:30 on fri, mon", want: "every 2 hours between 8am and 8:30pm on Monday and Friday"},
+		{schedule: "at noon and 6pm except on sundays", want: "at noon and 6pm on Monday, Tuesday, Wednesday, Thursday, Friday and Saturday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			rule, err := ParseSchedule(tt.schedule)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error = %v", err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+
+			again, err := ParseSchedule(rule.String())
+			if err != nil {
+				t.Fatalf("ParseSchedule(String()) error = %v", err)
+			}
+			if again.String() != rule.String() {
+				t.Errorf("round trip changed rule: %q -> %q", rule.String(), again.String())
+			}
+		})
+	}
+}
+
+func TestRuleFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ReminderConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "Interval", cfg: config.ReminderConfig{Interval: "30m"}, want: "at :00 and :30"},
+		{name: "Interval 45m keeps hourly alignment", cfg: config.ReminderConfig{Interval: "45m"}, want: "at :00 and :45"},
+		{name: "Trigger minutes", cfg: config.ReminderConfig{TriggerMinutes: []int{15}}, want: "at :15"},
+		{name: "Schedule overrides interval", cfg: config.ReminderConfig{Interval: "30m", Schedule: "every hour on weekends"}, want: "every hour on weekends"},
+		{name: "Invalid schedule", cfg: config.ReminderConfig{Schedule: "every blue moon"}, wantErr: true},
+		{name: "Invalid trigger minute", cfg: config.ReminderConfig{TriggerMinutes: []int{60}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ruleFromConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ruleFromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && rule.String() != tt.want {
+				t.Errorf("ruleFromConfig() = %q, want %q", rule.String(), tt.want)
+			}
+		})
+	}
+}
@@ -5,56 +5,314 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/message"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/state"
 )
 
 // Player defines the interface for audio playback.
 type Player interface {
-	Play() error
+	// Play plays the given sound file, or the configured sound if empty, at
+	// the given volume, or the configured volume if zero. It returns once the
+	// sound finished, or early when ctx is done.
+	Play(ctx context.Context, file string, volume float64) error
+	// Stop interrupts the sound being played.
 	Stop()
 }
 
 // Notifier defines the interface for desktop notifications.
 type Notifier interface {
-	Notify() error
+	// Notify shows the given title and message, or the configured ones if
+	// empty.
+	Notify(title, message string) error
 }
 
+// TimerSource provides one-off reminders scheduled outside the config.
+type TimerSource interface {
+	// TakeDueTimers removes and returns every timer due at or before now.
+	TakeDueTimers(now time.Time) ([]state.Timer, error)
+}
+
+// FocusSource provides the focus session started from the CLI.
+type FocusSource interface {
+	// Focus returns the current focus session, or nil if none is active.
+	Focus() (*state.Focus, error)
+	// FinishFocus ends the session f once it is over, unless it was stopped
+	// or replaced since it was read, and reports whether it ended.
+	FinishFocus(f state.Focus) (bool, error)
+}
+
+// Ambience plays the soundtrack of a break.
+type Ambience interface {
+	// HasAmbient reports whether a soundtrack is configured.
+	HasAmbient() bool
+	// PlayAmbient plays the soundtrack for d, returning early when ctx is done.
+	PlayAmbient(ctx context.Context, d time.Duration) error
+	// StopAmbient ends the soundtrack.
+	StopAmbient()
+}
+
+// Speaker reads messages aloud.
+type Speaker interface {
+	// Speak reads text aloud at the given volume, or the configured volume
+	// if zero.
+	Speak(ctx context.Context, text string, volume float64) error
+}
+
+// TipSource suggests something to do during a break.
+type TipSource interface {
+	// Suggest returns a tip that fits in a break of length d, or "" if none
+	// does.
+	Suggest(now time.Time, d time.Duration) string
+}
+
+// AckSource records when the user last acknowledged a reminder.
+type AckSource interface {
+	// Acknowledge records an acknowledgement at now.
+	Acknowledge(now time.Time) error
+	// LastAck returns the time of the last acknowledgement (zero if none).
+	LastAck() (time.Time, error)
+}
+
+// BreakSource records breaks so they can be ended from the CLI.
+type BreakSource interface {
+	// StartBreak records a break of duration d starting at now.
+	StartBreak(now time.Time, d time.Duration) (state.Break, error)
+	// Break returns the break in progress, or nil if it was ended.
+	Break() (*state.Break, error)
+	// StopBreak ends the break in progress.
+	StopBreak() error
+}
+
+// Trigger describes a single reminder and how it will be delivered.
+type Trigger struct {
+	// Time is when the reminder fires
+	Time time.Time
+	// Variant is the name of the matching variant (empty for the default)
+	Variant string
+	// Message overrides the notification message (empty for the default)
+	Message string
+	// Sound overrides the sound file (empty for the default)
+	Sound string
+	// Volume overrides the sound volume (zero for the default)
+	Volume float64
+	// Break is how long the break after the reminder lasts (zero for none)
+	Break time.Duration
+
+	// source is what fired the reminder
+	source triggerSource
+}
+
+// triggerSource is what fired a reminder.
+type triggerSource int
+
+const (
+	// sourceSchedule is a regular reminder of the schedule
+	sourceSchedule triggerSource = iota
+	// sourceFocus is the end of a focus session
+	sourceFocus
+	// sourceTimer is a one-off timer, whose message is shown as written
+	sourceTimer
+)
+
 // Scheduler manages the reminder timing and triggers notifications.
 type Scheduler struct {
 	config   config.ReminderConfig
 	player   Player
 	notifier Notifier
-	interval time.Duration
+	timers   TimerSource
+	rule     Rule
+	variants []variant
 	lastPlay time.Time
 	mu       sync.Mutex
+	// deliveries tracks reminders being delivered in the background
+	deliveries sync.WaitGroup
+
+	// System time zone tracking
+	zone    *zoneSource
+	loc     *time.Location
+	zoneErr string
+
+	// Focus session handling
+	focus       FocusSource
+	focusConfig config.FocusConfig
+	focusBreak  time.Duration
+	focusing    bool
+	breakUntil  time.Time
+
+	// Breaks with an ambient soundtrack
+	ambience      Ambience
+	breaks        BreakSource
+	breakDuration time.Duration
+	onBreak       atomic.Bool
+
+	// Spoken reminders
+	speaker Speaker
+
+	// Notification templates and what they can refer to
+	notification config.NotificationConfig
+	templates    config.Templates
+	countDay     string
+	count        int
+	workStart    time.Time
+	tips         TipSource
+
+	// Volume escalation of unacknowledged reminders
+	acks         AckSource
+	unacked      int
+	lastReminder time.Time
+
+	// Snoozing from the notification
+	lastTrigger Trigger
+	lastTitle   string
+	lastMessage string
+	snoozeUntil time.Time
+}
+
+// Option configures optional Scheduler behavior.
+type Option func(*Scheduler)
+
+// WithTimers makes the scheduler deliver one-off timers from src.
+func WithTimers(src TimerSource) Option {
+	return func(s *Scheduler) {
+		s.timers = src
+	}
+}
+
+// WithFocus makes the scheduler honor focus sessions from src.
+func WithFocus(src FocusSource, cfg config.FocusConfig) Option {
+	return func(s *Scheduler) {
+		s.focus = src
+		s.focusConfig = cfg
+	}
+}
+
+// WithAmbience plays the soundtrack of a during the break after each
+// reminder, recording breaks in src so they can be ended early.
+func WithAmbience(src BreakSource, a Ambience) Option {
+	return func(s *Scheduler) {
+		s.breaks = src
+		s.ambience = a
+	}
+}
+
+// WithAcks resets the volume escalation when src reports that the user
+// acknowledged a reminder, and records acknowledgements from the
+// notification buttons in it.
+func WithAcks(src AckSource) Option {
+	return func(s *Scheduler) {
+		s.acks = src
+	}
+}
+
+// WithSpeech reads each reminder's message aloud with sp after the
+// notification.
+func WithSpeech(sp Speaker) Option {
+	return func(s *Scheduler) {
+		s.speaker = sp
+	}
+}
+
+// WithNotification renders the title and message of cfg, and of variants
+// and focus sessions, with their parsed templates for each reminder.
+func WithNotification(cfg config.NotificationConfig, templates config.Templates) Option {
+	return func(s *Scheduler) {
+		s.notification = cfg
+		s.templates = templates
+	}
+}
+
+// WithTips adds a tip from src that fits the break to each reminder. It
+// is appended to the message unless the message template shows {{.Tip}}.
+func WithTips(src TipSource) Option {
+	return func(s *Scheduler) {
+		s.tips = src
+	}
 }
 
 // New creates a new Scheduler instance.
-func New(cfg config.ReminderConfig, player Player, notifier Notifier) *Scheduler {
-	return &Scheduler{
+func New(cfg config.ReminderConfig, player Player, notifier Notifier, opts ...Option) *Scheduler {
+	s := &Scheduler{
 		config:   cfg,
 		player:   player,
 		notifier: notifier,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// prepare parses the reminder configuration into its runtime form.
+func (s *Scheduler) prepare() error {
+	rule, err := ruleFromConfig(s.config)
+	if err != nil {
+		return err
+	}
+
+	variants, err := parseVariants(s.config.Variants)
+	if err != nil {
+		return fmt.Errorf("invalid variants: %w", err)
+	}
+
+	var breakDuration time.Duration
+	if s.config.BreakDuration != "" {
+		if breakDuration, err = time.ParseDuration(s.config.BreakDuration); err != nil || breakDuration < 0 {
+			return fmt.Errorf("invalid break duration %q", s.config.BreakDuration)
+		}
+	}
+
+	if e := s.config.Escalation; e.Enabled {
+		if e.Start <= 0 || e.Max > 1 || e.Start > e.Max || e.Step < 0 {
+			return fmt.Errorf("invalid escalation: need 0 < start <= max <= 1 and step >= 0, got start %v, step %v, max %v", e.Start, e.Step, e.Max)
+		}
+	}
+
+	if v := s.focusConfig.Volume; v < 0 || v > 1 {
+		return fmt.Errorf("invalid focus volume %v: must be between 0.0 and 1.0", v)
+	}
+
+	var focusBreak time.Duration
+	if s.focus != nil && s.focusConfig.BreakDuration != "" {
+		if focusBreak, err = time.ParseDuration(s.focusConfig.BreakDuration); err != nil {
+			return fmt.Errorf("invalid focus break duration: %w", err)
+		}
+	}
+
+	for i := range variants {
+		if i < len(s.templates.Variants) {
+			variants[i].template = s.templates.Variants[i]
+		}
+	}
+
+	s.rule = rule
+	s.variants = variants
+	s.focusBreak = focusBreak
+	s.breakDuration = breakDuration
+	return nil
 }
 
 // Run starts the scheduler loop and blocks until the context is cancelled.
 func (s *Scheduler) Run(ctx context.Context) error {
-	// Parse interval
-	interval, err := time.ParseDuration(s.config.Interval)
-	if err != nil {
-		return fmt.Errorf("invalid interval format: %w", err)
+	if err := s.prepare(); err != nil {
+		return err
 	}
-	s.interval = interval
 
 	slog.Info("scheduler started",
-		"interval", s.interval,
-		"trigger_minutes", s.config.TriggerMinutes,
+		"schedule", s.rule.String(),
+		"variants", len(s.variants),
 	)
 
+	if s.zone == nil {
+		s.zone = newSystemZone()
+	}
+	s.workStart = s.localize(time.Now())
+
 	// Use 1-second ticker for precise timing
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -63,12 +321,18 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			slog.Info("scheduler stopping")
+			// Sounds being played see the cancelled context and stop
+			s.deliveries.Wait()
 			return nil
 		case now := <-ticker.C:
-			if s.shouldTrigger(now) {
+			now = s.localize(now)
+			if !s.updateFocus(ctx, now) && s.shouldTrigger(now) {
 				// Run trigger asynchronously to prevent blocking the loop
-				go s.trigger(now)
+				s.deliveries.Go(func() { s.trigger(ctx, now) })
 			}
+			s.fireTimers(ctx, now)
+			s.fireSnoozed(ctx, now)
+			s.checkBreak()
 		}
 	}
 }
@@ -85,46 +349,416 @@ func (s *Scheduler) shouldTrigger(now time.Time) bool {
 		return false
 	}
 
-	// If specific trigger minutes are configured, check against them
-	if len(s.config.TriggerMinutes) > 0 {
-		currentMinute := now.Minute()
-		for _, m := range s.config.TriggerMinutes {
-			if currentMinute == m {
-				return true
-			}
+	return s.matches(now)
+}
+
+// matches reports whether the schedule fires in the minute containing now.
+func (s *Scheduler) matches(now time.Time) bool {
+	return s.rule.Matches(now)
+}
+
+// Rule returns the parsed schedule rule of the reminder configuration.
+func (s *Scheduler) Rule() (Rule, error) {
+	if err := s.prepare(); err != nil {
+		return Rule{}, err
+	}
+	return s.rule, nil
+}
+
+// triggerAt builds the Trigger for a reminder firing at t.
+func (s *Scheduler) triggerAt(t time.Time) Trigger {
+	tr := Trigger{Time: t, Break: s.breakDuration}
+	if v := selectVariant(s.variants, t); v != nil {
+		tr.Variant = v.name
+		tr.Message = v.message
+		tr.Sound = v.sound
+		tr.Volume = v.volume
+	}
+	return tr
+}
+
+// Next returns the next n reminders strictly after from.
+func (s *Scheduler) Next(from time.Time, n int) ([]Trigger, error) {
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+	return s.upcoming(from, n), nil
+}
+
+// upcoming returns the next n reminders strictly after from, in from's location.
+func (s *Scheduler) upcoming(from time.Time, n int) []Trigger {
+	// A week covers every schedule shape, so give up after that
+	limit := from.AddDate(0, 0, 8)
+	var triggers []Trigger
+	for t := from.Truncate(time.Minute).Add(time.Minute); len(triggers) < n && t.Before(limit); t = t.Add(time.Minute) {
+		if s.matches(t) {
+			triggers = append(triggers, s.triggerAt(t))
 		}
-		return false
 	}
+	return triggers
+}
 
-	// Otherwise, use interval-based triggering
-	minutes := now.Minute()
-	intervalMinutes := int(s.interval.Minutes())
-	if intervalMinutes <= 0 {
-		intervalMinutes = 30 // Default to 30 minutes
+// Simulate returns every reminder in the half-open range [from, to).
+func (s *Scheduler) Simulate(from, to time.Time) ([]Trigger, error) {
+	if err := s.prepare(); err != nil {
+		return nil, err
 	}
 
-	// Trigger if the current minute aligns with the interval
-	// Note: We don't check seconds here because the debouncing (lastPlay check) above handles it.
-	return minutes%intervalMinutes == 0
+	var triggers []Trigger
+	start := from.Truncate(time.Minute)
+	if start.Before(from) {
+		start = start.Add(time.Minute)
+	}
+	for t := start; t.Before(to); t = t.Add(time.Minute) {
+		if s.matches(t) {
+			triggers = append(triggers, s.triggerAt(t))
+		}
+	}
+	return triggers, nil
 }
 
 // trigger executes the reminder notification.
-func (s *Scheduler) trigger(now time.Time) {
+func (s *Scheduler) trigger(ctx context.Context, now time.Time) {
 	s.mu.Lock()
 	s.lastPlay = now
 	s.mu.Unlock()
 
+	tr := s.triggerAt(now)
+	if s.config.Escalation.Enabled {
+		tr.Volume = s.escalate(now)
+	}
 	slog.Info("🔔 reminder triggered",
 		"time", now.Format("15:04:05"),
+		"variant", tr.Variant,
+	)
+
+	s.deliver(ctx, tr)
+}
+
+// escalate returns the volume of a reminder at now: each reminder since the
+// last acknowledgement is a step louder than the one before, up to the
+// maximum.
+func (s *Scheduler) escalate(now time.Time) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.acks != nil {
+		at, err := s.acks.LastAck()
+		if err != nil {
+			slog.Error("failed to read acknowledgement", "error", err)
+		} else if at.After(s.lastReminder) {
+			s.unacked = 0
+		}
+	}
+
+	e := s.config.Escalation
+	volume := min(e.Start+e.Step*float64(s.unacked), e.Max)
+	if s.unacked > 0 {
+		slog.Info("📢 escalating unacknowledged reminder", "unacknowledged", s.unacked, "volume", volume)
+	}
+	s.unacked++
+	s.lastReminder = now
+	return volume
+}
+
+// localize converts now to the system time zone. When the zone changed
+// since the last tick, it reloads the location and recomputes the next
+// reminder in the new zone.
+func (s *Scheduler) localize(now time.Time) time.Time {
+	loc, changed, err := s.zone.Check()
+	if err != nil {
+		// Only log each distinct error once, as this runs on every tick
+		if err.Error() != s.zoneErr {
+			slog.Error("failed to load system time zone", "error", err)
+			s.zoneErr = err.Error()
+		}
+	} else {
+		s.zoneErr = ""
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	previous := s.loc
+	s.loc = loc
+	now = now.In(loc)
+
+	if changed {
+		next := "none"
+		if upcoming := s.upcoming(now, 1); len(upcoming) > 0 {
+			next = upcoming[0].Time.Format("2006-01-02 15:04 MST")
+		}
+		slog.Info("🌐 system time zone changed",
+			"from", previous,
+			"to", loc,
+			"local_time", now.Format("15:04:05 MST"),
+			"next_reminder", next,
+		)
+	}
+	return now
+}
+
+// updateFocus tracks the focus session and reports whether regular
+// reminders are suppressed at now. When a session ends, it fires the
+// focus reminder and keeps reminders paused for the focus break.
+func (s *Scheduler) updateFocus(ctx context.Context, now time.Time) bool {
+	if s.focus == nil {
+		return false
+	}
+
+	f, err := s.focus.Focus()
+	if err != nil {
+		slog.Error("failed to read focus session", "error", err)
+		return false
+	}
+
+	if f == nil {
+		if s.focusing {
+			slog.Info("focus session stopped")
+			s.focusing = false
+		}
+		return now.Before(s.breakUntil)
+	}
+
+	if now.Before(f.End) {
+		if !s.focusing {
+			slog.Info("🎯 focus session active, reminders paused", "until", f.End.Format("15:04:05"))
+			s.focusing = true
+		}
+		return true
+	}
+
+	ended, err := s.focus.FinishFocus(*f)
+	if err != nil {
+		slog.Error("failed to clear focus session", "error", err)
+		return true
+	}
+	if !ended {
+		// A new session was started or this one stopped since it was read;
+		// the next tick picks that up
+		slog.Debug("focus session changed before it could be finished")
+		return true
+	}
+	s.focusing = false
+	s.breakUntil = now.Add(s.focusBreak)
+
+	slog.Info("🎯 focus session finished",
+		"duration", f.End.Sub(f.Start).Round(time.Second),
+		"break_until", s.breakUntil.Format("15:04:05"),
 	)
+	tr := Trigger{
+		Time:    now,
+		Variant: "focus",
+		Message: s.focusConfig.Message,
+		Sound:   s.focusConfig.Sound,
+		Volume:  s.focusConfig.Volume,
+		Break:   s.focusBreak,
+		source:  sourceFocus,
+	}
+	s.deliveries.Go(func() { s.deliver(ctx, tr) })
+	return true
+}
+
+// fireTimers delivers every one-off timer that is due.
+// Timers that came due while the instance was not running fire late.
+func (s *Scheduler) fireTimers(ctx context.Context, now time.Time) {
+	if s.timers == nil {
+		return
+	}
 
-	// Play sound
-	if err := s.player.Play(); err != nil {
-		slog.Error("failed to play sound", "error", err)
+	due, err := s.timers.TakeDueTimers(now)
+	if err != nil {
+		slog.Error("failed to read timers", "error", err)
+		return
 	}
 
+	for _, t := range due {
+		slog.Info("⏰ timer fired",
+			"id", t.ID,
+			"scheduled", t.At.Format("15:04:05"),
+			"message", t.Message,
+		)
+		tr := Trigger{Time: now, Message: t.Message, source: sourceTimer}
+		s.deliveries.Go(func() { s.deliver(ctx, tr) })
+	}
+}
+
+// deliver plays the sound and shows the notification for a reminder.
+func (s *Scheduler) deliver(ctx context.Context, tr Trigger) {
+	// Don't pop up notifications while shutting down
+	if ctx.Err() != nil {
+		return
+	}
+
+	title, msg := s.render(tr)
+	s.mu.Lock()
+	s.lastTrigger, s.lastTitle, s.lastMessage = tr, title, msg
+	s.mu.Unlock()
+
+	s.remind(ctx, tr, title, msg)
+}
+
+// remind plays the sound of tr and shows its rendered notification, then
+// reads it aloud and takes the break.
+func (s *Scheduler) remind(ctx context.Context, tr Trigger, title, msg string) {
+	// Play the sound alongside the notification, so a long sound such as a
+	// routine does not hold back the notification and its buttons
+	played := make(chan struct{})
+	go func() {
+		defer close(played)
+		if err := s.player.Play(ctx, tr.Sound, tr.Volume); err != nil {
+			slog.Error("failed to play sound", "error", err)
+		}
+	}()
+
 	// Show desktop notification
-	if err := s.notifier.Notify(); err != nil {
+	if err := s.notifier.Notify(title, msg); err != nil {
 		slog.Error("failed to show notification", "error", err)
 	}
+
+	// Speech and the break follow the sound
+	<-played
+	if ctx.Err() != nil {
+		return
+	}
+
+	if s.speaker != nil {
+		if err := s.speaker.Speak(ctx, msg, tr.Volume); err != nil {
+			slog.Error("failed to speak reminder", "error", err)
+		}
+	}
+
+	if tr.Break > 0 && s.ambience != nil {
+		s.takeBreak(ctx, tr)
+	}
+}
+
+// render returns the title and message of a reminder, filling in the
+// configured templates. A timer's own message is shown as written.
+func (s *Scheduler) render(tr Trigger) (title, msg string) {
+	data := s.messageData(tr)
+
+	msg = tr.Message
+	if msg == "" {
+		msg = s.notification.Message
+	}
+	title = renderText(s.templates.Title, s.notification.Title, data)
+	if tr.source != sourceTimer || tr.Message == "" {
+		msg = renderText(s.messageTemplate(tr), msg, data)
+	}
+	if data.Tip != "" && !strings.Contains(msg, data.Tip) {
+		msg += "\n💡 " + data.Tip
+	}
+	return title, msg
+}
+
+// messageTemplate returns the template of the message of a scheduled
+// reminder or focus session: its own, or the configured one if it has no
+// message.
+func (s *Scheduler) messageTemplate(tr Trigger) *message.Template {
+	var tmpl *message.Template
+	switch tr.source {
+	case sourceFocus:
+		tmpl = s.templates.Focus
+	case sourceSchedule:
+		if v := selectVariant(s.variants, tr.Time); v != nil {
+			tmpl = v.template
+		}
+	}
+	if tmpl == nil && tr.Message == "" {
+		tmpl = s.templates.Message
+	}
+	return tmpl
+}
+
+// renderText renders tmpl, returning text unchanged if there is no
+// template or it fails to render.
+func renderText(tmpl *message.Template, text string, data message.Data) string {
+	if tmpl == nil {
+		return text
+	}
+	out, err := tmpl.Render(data)
+	if err != nil {
+		slog.Error("failed to render message", "error", err)
+		return text
+	}
+	return out
+}
+
+// messageData returns what templates can refer to for tr. Scheduled
+// reminders count as one of today's reminders and start a new stretch of
+// work after their break.
+func (s *Scheduler) messageData(tr Trigger) message.Data {
+	data := message.Data{Time: tr.Time, Reminder: tr.Variant, Break: tr.Break}
+	if next := s.upcoming(tr.Time, 1); len(next) > 0 {
+		data.Next = next[0].Time
+	}
+	// Timers and reminders without a break get no tip
+	if s.tips != nil && tr.Break > 0 {
+		data.Tip = s.tips.Suggest(tr.Time, tr.Break)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if day := tr.Time.Format(time.DateOnly); day != s.countDay {
+		s.countDay, s.count = day, 0
+	}
+	if !s.workStart.IsZero() && tr.Time.After(s.workStart) {
+		data.Worked = tr.Time.Sub(s.workStart)
+	}
+	if tr.source == sourceSchedule {
+		s.count++
+		if tr.Break > 0 {
+			s.workStart = tr.Time.Add(tr.Break)
+		}
+	}
+	data.Count = s.count
+	return data
+}
+
+// takeBreak plays the ambient soundtrack for the break after a reminder.
+// A break that is still going on is left alone, and no break is taken
+// without a soundtrack.
+func (s *Scheduler) takeBreak(ctx context.Context, tr Trigger) {
+	if !s.ambience.HasAmbient() {
+		return
+	}
+	if !s.onBreak.CompareAndSwap(false, true) {
+		slog.Debug("break already in progress", "variant", tr.Variant)
+		return
+	}
+	defer s.onBreak.Store(false)
+
+	if s.breaks != nil {
+		if _, err := s.breaks.StartBreak(tr.Time, tr.Break); err != nil {
+			slog.Error("failed to record break", "error", err)
+		}
+		defer func() {
+			if err := s.breaks.StopBreak(); err != nil {
+				slog.Error("failed to clear break", "error", err)
+			}
+		}()
+	}
+
+	if err := s.ambience.PlayAmbient(ctx, tr.Break); err != nil && ctx.Err() == nil {
+		slog.Error("failed to play ambient sound", "error", err)
+	}
+}
+
+// checkBreak stops the ambient soundtrack when the break was ended from
+// the CLI.
+func (s *Scheduler) checkBreak() {
+	if s.breaks == nil || !s.onBreak.Load() {
+		return
+	}
+
+	b, err := s.breaks.Break()
+	if err != nil {
+		slog.Error("failed to read break", "error", err)
+		return
+	}
+	if b == nil {
+		s.ambience.StopAmbient()
+	}
 }
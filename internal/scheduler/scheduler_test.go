@@ -1,19 +1,27 @@
 package scheduler
 
 import (
+	"context"
+	"math"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/state"
 )
 
 // MockPlayer implements Player interface for testing
 type MockPlayer struct {
-	PlayCount int
+	PlayCount  int
+	LastFile   string
+	LastVolume float64
 }
 
-func (m *MockPlayer) Play() error {
+func (m *MockPlayer) Play(_ context.Context, file string, volume float64) error {
 	m.PlayCount++
+	m.LastFile = file
+	m.LastVolume = volume
 	return nil
 }
 func (m *MockPlayer) Stop() {}
@@ -21,10 +29,14 @@ func (m *MockPlayer) Stop() {}
 // MockNotifier implements Notifier interface for testing
 type MockNotifier struct {
 	NotifyCount int
+	LastTitle   string
+	LastMessage string
 }
 
-func (m *MockNotifier) Notify() error {
+func (m *MockNotifier) Notify(title, message string) error {
 	m.NotifyCount++
+	m.LastTitle = title
+	m.LastMessage = message
 	return nil
 }
 
@@ -90,9 +102,8 @@ func TestScheduler_shouldTrigger(t *testing.T) {
 			s := New(tt.cfg, &MockPlayer{}, &MockNotifier{})
 
 			// Manual setup for test since they are private fields in same package
-			if tt.cfg.Interval != "" {
-				d, _ := time.ParseDuration(tt.cfg.Interval)
-				s.interval = d
+			if err := s.prepare(); err != nil {
+				t.Fatalf("prepare() error = %v", err)
 			}
 			s.lastPlay = tt.lastPlay
 
@@ -102,3 +113,601 @@ func TestScheduler_shouldTrigger(t *testing.T) {
 		})
 	}
 }
+
+// MockTimers implements TimerSource for testing
+type MockTimers struct {
+	Due []state.Timer
+}
+
+func (m *MockTimers) TakeDueTimers(now time.Time) ([]state.Timer, error) {
+	var due, pending []state.Timer
+	for _, t := range m.Due {
+		if t.At.After(now) {
+			pending = append(pending, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	m.Due = pending
+	return due, nil
+}
+
+// ChanNotifier implements Notifier by sending messages on a channel
+type ChanNotifier chan string
+
+func (c ChanNotifier) Notify(_, message string) error {
+	c <- message
+	return nil
+}
+
+func TestScheduler_fireTimers(t *testing.T) {
+	now := time.Date(2023, 1, 1, 10, 7, 0, 0, time.UTC)
+	timers := &MockTimers{Due: []state.Timer{
+		{ID: "1", At: now.Add(-time.Minute), Message: "check the oven"},
+		{ID: "2", At: now.Add(time.Hour), Message: "later"},
+	}}
+	notifier := make(ChanNotifier, 2)
+
+	s := New(config.ReminderConfig{Interval: "30m"}, &MockPlayer{}, notifier, WithTimers(timers))
+	s.fireTimers(context.Background(), now)
+
+	select {
+	case msg := <-notifier:
+		if msg != "check the oven" {
+			t.Errorf("expected timer message, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timer was not delivered")
+	}
+
+	s.fireTimers(context.Background(), now)
+	select {
+	case msg := <-notifier:
+		t.Errorf("expected timer to be delivered once, got extra %q", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if len(timers.Due) != 1 || timers.Due[0].ID != "2" {
+		t.Errorf("expected only the future timer to remain, got %+v", timers.Due)
+	}
+}
+
+// MockFocus implements FocusSource for testing
+type MockFocus struct {
+	Session *state.Focus
+}
+
+func (m *MockFocus) Focus() (*state.Focus, error) {
+	return m.Session, nil
+}
+
+func (m *MockFocus) FinishFocus(f state.Focus) (bool, error) {
+	if m.Session == nil || *m.Session != f {
+		return false, nil
+	}
+	m.Session = nil
+	return true, nil
+}
+
+func TestScheduler_updateFocus(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	focus := &MockFocus{Session: &state.Focus{Start: start, End: start.Add(50 * time.Minute)}}
+	notifier := make(ChanNotifier, 1)
+	player := &MockPlayer{}
+	focusCfg := config.FocusConfig{Message: "Focus done", Sound: "focus.wav", BreakDuration: "15m"}
+
+	s := New(config.ReminderConfig{Interval: "30m"}, player, notifier, WithFocus(focus, focusCfg))
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	if !s.updateFocus(context.Background(), start.Add(30*time.Minute)) {
+		t.Error("expected reminders to be suppressed during the session")
+	}
+
+	if !s.updateFocus(context.Background(), start.Add(50*time.Minute)) {
+		t.Error("expected reminders to be suppressed when the session ends")
+	}
+	select {
+	case msg := <-notifier:
+		if msg != "Focus done" {
+			t.Errorf("expected focus message, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("focus reminder was not delivered")
+	}
+	s.deliveries.Wait()
+	if player.LastFile != "focus.wav" {
+		t.Errorf("expected focus sound, got %q", player.LastFile)
+	}
+	if focus.Session != nil {
+		t.Error("expected focus session to be cleared")
+	}
+
+	if !s.updateFocus(context.Background(), start.Add(60*time.Minute)) {
+		t.Error("expected reminders to be suppressed during the focus break")
+	}
+	if s.updateFocus(context.Background(), start.Add(65*time.Minute)) {
+		t.Error("expected regular reminders to resume after the focus break")
+	}
+}
+
+// racingFocus starts a new session from "the CLI" right before an expired
+// one is finished.
+type racingFocus struct {
+	*state.Store
+	next time.Time
+}
+
+func (r racingFocus) FinishFocus(f state.Focus) (bool, error) {
+	if _, err := r.StartFocus(r.next, 50*time.Minute); err != nil {
+		return false, err
+	}
+	return r.Store.FinishFocus(f)
+}
+
+func TestScheduler_updateFocus_Restarted(t *testing.T) {
+	store, err := state.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := store.StartFocus(start, 50*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	notifier := &MockNotifier{}
+	s := New(config.ReminderConfig{Interval: "30m"}, &MockPlayer{}, notifier,
+		WithFocus(racingFocus{Store: store, next: start.Add(50 * time.Minute)}, config.FocusConfig{Message: "Focus done"}),
+	)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	if !s.updateFocus(context.Background(), start.Add(50*time.Minute)) {
+		t.Error("expected reminders to stay suppressed")
+	}
+	s.deliveries.Wait()
+	if notifier.NotifyCount != 0 {
+		t.Errorf("expected no focus reminder, got %d", notifier.NotifyCount)
+	}
+	if f, err := store.Focus(); err != nil || f == nil {
+		t.Errorf("expected the new session to be kept, got %+v (err %v)", f, err)
+	}
+}
+
+// BlockingPlayer plays until its context is done.
+type BlockingPlayer struct {
+	started chan struct{}
+	stopped chan error
+}
+
+func (b *BlockingPlayer) Play(ctx context.Context, _ string, _ float64) error {
+	close(b.started)
+	<-ctx.Done()
+	b.stopped <- ctx.Err()
+	return ctx.Err()
+}
+
+func (b *BlockingPlayer) Stop() {}
+
+func TestScheduler_Run_ShutdownDuringPlayback(t *testing.T) {
+	player := &BlockingPlayer{started: make(chan struct{}), stopped: make(chan error, 1)}
+	notifier := &MockNotifier{}
+	s := New(config.ReminderConfig{Schedule: "every minute"}, player, notifier)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	select {
+	case <-player.started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("reminder did not start playing")
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return while a sound was playing")
+	}
+
+	// Run waits for the delivery, so playback has already ended
+	select {
+	case err := <-player.stopped:
+		if err != context.Canceled {
+			t.Errorf("expected playback to be cancelled, got %v", err)
+		}
+	default:
+		t.Error("Run returned before playback ended")
+	}
+	// The notification went out as the sound started, and only once
+	if notifier.NotifyCount != 1 {
+		t.Errorf("expected the notification alongside the sound, got %d", notifier.NotifyCount)
+	}
+}
+
+func TestScheduler_deliver_NotifiesDuringSound(t *testing.T) {
+	player := &BlockingPlayer{started: make(chan struct{}), stopped: make(chan error, 1)}
+	notifier := make(ChanNotifier, 1)
+	s := New(config.ReminderConfig{Interval: "30m"}, player, notifier)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	// A long sound, such as a routine, does not hold back the notification
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.deliver(ctx, Trigger{Time: time.Now(), Message: "stretch"})
+	}()
+	select {
+	case msg := <-notifier:
+		if msg != "stretch" {
+			t.Errorf("expected the reminder, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no notification while the sound was playing")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestScheduler_deliver_ShuttingDown(t *testing.T) {
+	notifier := &MockNotifier{}
+	s := New(config.ReminderConfig{Interval: "30m"}, &MockPlayer{}, notifier)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.deliver(ctx, Trigger{Time: time.Now()})
+	if notifier.NotifyCount != 0 {
+		t.Errorf("expected no notification during shutdown, got %d", notifier.NotifyCount)
+	}
+}
+
+// MockAmbience plays until stopped or its context is done.
+type MockAmbience struct {
+	silent   bool
+	started  chan time.Duration
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+func (m *MockAmbience) HasAmbient() bool {
+	return !m.silent
+}
+
+func (m *MockAmbience) PlayAmbient(ctx context.Context, d time.Duration) error {
+	m.started <- d
+	select {
+	case <-m.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *MockAmbience) StopAmbient() {
+	m.stopOnce.Do(func() { close(m.stopped) })
+}
+
+func TestScheduler_deliver_Break(t *testing.T) {
+	store, err := state.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ambience := &MockAmbience{started: make(chan time.Duration, 1), stopped: make(chan struct{})}
+	s := New(config.ReminderConfig{Interval: "30m", BreakDuration: "5m"}, &MockPlayer{}, &MockNotifier{},
+		WithAmbience(store, ambience),
+	)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	now := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.deliver(context.Background(), s.triggerAt(now))
+	}()
+
+	select {
+	case d := <-ambience.started:
+		if d != 5*time.Minute {
+			t.Errorf("expected a 5m break, got %v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ambient sound did not start")
+	}
+	if b, err := store.Break(); err != nil || b == nil || !b.End.Equal(now.Add(5*time.Minute)) {
+		t.Fatalf("expected break until 10:05, got %+v (err %v)", b, err)
+	}
+
+	// The break keeps going until it is ended from the CLI
+	s.checkBreak()
+	select {
+	case <-done:
+		t.Fatal("break ended early")
+	default:
+	}
+	if err := store.StopBreak(); err != nil {
+		t.Fatal(err)
+	}
+	s.checkBreak()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ambient sound was not stopped")
+	}
+	if s.onBreak.Load() {
+		t.Error("expected break to be over")
+	}
+}
+
+func TestScheduler_deliver_NoBreak(t *testing.T) {
+	ambience := &MockAmbience{started: make(chan time.Duration, 1), stopped: make(chan struct{})}
+	s := New(config.ReminderConfig{Interval: "30m"}, &MockPlayer{}, &MockNotifier{},
+		WithAmbience(nil, ambience),
+	)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	// Timers and reminders without a break duration play no soundtrack
+	s.deliver(context.Background(), Trigger{Time: time.Now(), Message: "check the oven"})
+	s.deliver(context.Background(), s.triggerAt(time.Now()))
+	select {
+	case <-ambience.started:
+		t.Error("expected no ambient sound")
+	default:
+	}
+}
+
+func TestScheduler_deliver_NoAmbientSound(t *testing.T) {
+	store, err := state.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ambience := &MockAmbience{silent: true, started: make(chan time.Duration, 1), stopped: make(chan struct{})}
+	s := New(config.ReminderConfig{Interval: "30m", BreakDuration: "5m"}, &MockPlayer{}, &MockNotifier{},
+		WithAmbience(store, ambience),
+	)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	// Without a soundtrack there is no break to record or end
+	s.deliver(context.Background(), s.triggerAt(time.Now()))
+	select {
+	case <-ambience.started:
+		t.Error("expected no ambient sound")
+	default:
+	}
+	if b, err := store.Break(); err != nil || b != nil {
+		t.Errorf("expected no break, got %+v (err %v)", b, err)
+	}
+	if s.onBreak.Load() {
+		t.Error("expected no break in progress")
+	}
+}
+
+// MockSpeaker records what it was asked to say.
+type MockSpeaker struct {
+	texts   []string
+	volumes []float64
+}
+
+func (m *MockSpeaker) Speak(_ context.Context, text string, volume float64) error {
+	m.texts = append(m.texts, text)
+	m.volumes = append(m.volumes, volume)
+	return nil
+}
+
+func TestScheduler_deliver_Speech(t *testing.T) {
+	speaker := &MockSpeaker{}
+	s := New(config.ReminderConfig{Interval: "30m"}, &MockPlayer{}, &MockNotifier{},
+		WithSpeech(speaker),
+		withNotification(t, config.NotificationConfig{Message: "Time to rest"}),
+	)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	// Reminders without their own message speak the default one
+	s.deliver(context.Background(), Trigger{Time: time.Now(), Message: "check the oven", Volume: 0.4})
+	s.deliver(context.Background(), Trigger{Time: time.Now()})
+	want := []string{"check the oven", "Time to rest"}
+	if len(speaker.texts) != 2 || speaker.texts[0] != want[0] || speaker.texts[1] != want[1] {
+		t.Fatalf("expected %q, got %q", want, speaker.texts)
+	}
+	if speaker.volumes[0] != 0.4 || speaker.volumes[1] != 0 {
+		t.Errorf("expected volumes [0.4 0], got %v", speaker.volumes)
+	}
+
+	// Nothing is said while shutting down
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.deliver(ctx, Trigger{Time: time.Now()})
+	if len(speaker.texts) != 2 {
+		t.Errorf("expected nothing said after shutdown, got %q", speaker.texts)
+	}
+}
+
+// MockAcks reports the last acknowledgement time it was given.
+type MockAcks struct {
+	at time.Time
+}
+
+func (m *MockAcks) Acknowledge(now time.Time) error {
+	m.at = now
+	return nil
+}
+
+func (m *MockAcks) LastAck() (time.Time, error) {
+	return m.at, nil
+}
+
+func TestScheduler_trigger_Escalation(t *testing.T) {
+	acks := &MockAcks{}
+	player := &MockPlayer{}
+	cfg := config.ReminderConfig{
+		Interval:   "30m",
+		Variants:   testVariants,
+		Escalation: config.EscalationConfig{Enabled: true, Start: 0.3, Step: 0.2, Max: 0.8},
+	}
+	s := New(cfg, player, &MockNotifier{}, WithAcks(acks))
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	start := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+	steps := []struct {
+		ack    bool
+		volume float64
+	}{
+		// Escalation replaces the volume of the morning variant
+		{volume: 0.3},
+		{volume: 0.5},
+		{volume: 0.7},
+		{volume: 0.8},
+		{volume: 0.8},
+		// Acknowledging starts over
+		{ack: true, volume: 0.3},
+		{volume: 0.5},
+	}
+	for i, step := range steps {
+		now := start.Add(time.Duration(i) * 30 * time.Minute)
+		if step.ack {
+			acks.at = now.Add(-time.Minute)
+		}
+		s.trigger(context.Background(), now)
+		if math.Abs(player.LastVolume-step.volume) > 1e-9 {
+			t.Errorf("reminder %d: expected volume %v, got %v", i+1, step.volume, player.LastVolume)
+		}
+	}
+}
+
+func TestScheduler_prepare_InvalidEscalation(t *testing.T) {
+	tests := []config.EscalationConfig{
+		{Enabled: true, Start: 0, Step: 0.1, Max: 1},
+		{Enabled: true, Start: 0.5, Step: 0.1, Max: 0.4},
+		{Enabled: true, Start: 0.3, Step: -0.1, Max: 1},
+		{Enabled: true, Start: 0.3, Step: 0.1, Max: 2},
+	}
+
+	for _, e := range tests {
+		s := New(config.ReminderConfig{Interval: "30m", Escalation: e}, &MockPlayer{}, &MockNotifier{})
+		if err := s.prepare(); err == nil {
+			t.Errorf("expected error for %+v", e)
+		}
+	}
+}
+
+func TestScheduler_prepare_InvalidFocusVolume(t *testing.T) {
+	for _, volume := range []float64{-0.1, 1.5} {
+		s := New(config.ReminderConfig{Interval: "30m"}, &MockPlayer{}, &MockNotifier{},
+			WithFocus(&MockFocus{}, config.FocusConfig{Volume: volume}),
+		)
+		if err := s.prepare(); err == nil {
+			t.Errorf("expected error for focus volume %v", volume)
+		}
+	}
+}
+
+// withNotification renders notifications with cfg, parsing its templates.
+func withNotification(t *testing.T, cfg config.NotificationConfig) Option {
+	t.Helper()
+	templates, err := (&config.Config{Notification: cfg}).ParseTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return WithNotification(cfg, templates)
+}
+
+func TestScheduler_deliver_Templates(t *testing.T) {
+	notifier := &MockNotifier{}
+	s := New(config.ReminderConfig{Interval: "30m", BreakDuration: "5m"}, &MockPlayer{}, notifier,
+		withNotification(t, config.NotificationConfig{
+			Title:   "Break #{{.Count}}",
+			Message: "{{minutes .Break}} min break after {{minutes .Worked}} min, next at {{clock .Next}}",
+		}),
+	)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.workStart = day.Add(9*time.Hour + 40*time.Minute)
+
+	tests := []struct {
+		name    string
+		tr      Trigger
+		title   string
+		message string
+	}{
+		{name: "First", tr: s.triggerAt(day.Add(10 * time.Hour)), title: "Break #1", message: "5 min break after 20 min, next at 10:30"},
+		{name: "After a break", tr: s.triggerAt(day.Add(10*time.Hour + 30*time.Minute)), title: "Break #2", message: "5 min break after 25 min, next at 11:00"},
+		{name: "Timer shown as written", tr: Trigger{Time: day.Add(10*time.Hour + 40*time.Minute), Message: "{{.Count}} eggs", source: sourceTimer}, title: "Break #2", message: "{{.Count}} eggs"},
+		{name: "Timer without a message", tr: Trigger{Time: day.Add(10*time.Hour + 45*time.Minute), source: sourceTimer}, title: "Break #2", message: "0 min break after 10 min, next at 11:00"},
+		{name: "Focus not counted", tr: Trigger{Time: day.Add(10*time.Hour + 50*time.Minute), Break: 10 * time.Minute, source: sourceFocus}, title: "Break #2", message: "10 min break after 15 min, next at 11:00"},
+		{name: "Next day", tr: s.triggerAt(day.Add(33 * time.Hour)), title: "Break #1", message: "5 min break after 1345 min, next at 09:30"},
+	}
+	for _, tt := range tests {
+		s.deliver(context.Background(), tt.tr)
+		if notifier.LastTitle != tt.title || notifier.LastMessage != tt.message {
+			t.Errorf("%s: expected %q / %q, got %q / %q", tt.name, tt.title, tt.message, notifier.LastTitle, notifier.LastMessage)
+		}
+	}
+}
+
+// MockTips suggests the same tip for every break and records its length.
+type MockTips struct {
+	breaks []time.Duration
+}
+
+func (m *MockTips) Suggest(_ time.Time, d time.Duration) string {
+	m.breaks = append(m.breaks, d)
+	return "Roll your shoulders."
+}
+
+func TestScheduler_deliver_Tips(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		tr      Trigger
+		want    string
+		breaks  int
+	}{
+		{name: "Appended", message: "Time to rest", tr: Trigger{Break: 5 * time.Minute}, want: "Time to rest\n💡 Roll your shoulders.", breaks: 1},
+		{name: "In template", message: "Try this: {{.Tip}}", tr: Trigger{Break: 5 * time.Minute}, want: "Try this: Roll your shoulders.", breaks: 1},
+		{name: "No break", message: "Time to rest", tr: Trigger{Message: "check the oven", source: sourceTimer}, want: "check the oven"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &MockNotifier{}
+			tips := &MockTips{}
+			s := New(config.ReminderConfig{Interval: "30m"}, &MockPlayer{}, notifier,
+				withNotification(t, config.NotificationConfig{Message: tt.message}),
+				WithTips(tips),
+			)
+			if err := s.prepare(); err != nil {
+				t.Fatalf("prepare() error = %v", err)
+			}
+
+			tt.tr.Time = time.Now()
+			s.deliver(context.Background(), tt.tr)
+			if notifier.LastMessage != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, notifier.LastMessage)
+			}
+			if len(tips.breaks) != tt.breaks || (tt.breaks > 0 && tips.breaks[0] != tt.tr.Break) {
+				t.Errorf("expected %d tips for a %v break, got %v", tt.breaks, tt.tr.Break, tips.breaks)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// zoneSource tracks the system time zone, which is configured by the TZ
+// environment variable or the /etc/localtime file. Unlike time.Local, it
+// notices when either changes while the process is running.
+type zoneSource struct {
+	// localtime is the path of the system zone file, usually a symlink
+	localtime string
+	// root is the zoneinfo directory used to resolve zone names
+	root string
+	// lookupEnv reads environment variables
+	lookupEnv func(string) (string, bool)
+
+	// tz, tzSet and localtimeInfo are the configuration loc was loaded
+	// from; localtimeInfo is nil if the file is missing or TZ is set
+	tz            string
+	tzSet         bool
+	localtimeInfo os.FileInfo
+	loaded        bool
+	loc           *time.Location
+}
+
+// newSystemZone returns a zoneSource for the running system.
+func newSystemZone() *zoneSource {
+	root := "/usr/share/zoneinfo"
+	if dir := os.Getenv("ZONEINFO"); dir != "" {
+		root = dir
+	}
+	return &zoneSource{
+		localtime: "/etc/localtime",
+		root:      root,
+		lookupEnv: os.LookupEnv,
+		loc:       time.Local,
+	}
+}
+
+// Check reloads the location if the zone configuration changed since the
+// last call, and reports whether it did. The first call always loads.
+// It runs on every scheduler tick, so it only reads TZ and the link
+// itself, which is replaced when the zone is changed; zone data is only
+// read again after that.
+func (z *zoneSource) Check() (*time.Location, bool, error) {
+	tz, tzSet := z.lookupEnv("TZ")
+	var info os.FileInfo
+	if !tzSet {
+		if fi, err := os.Lstat(z.localtime); err == nil {
+			info = fi
+		}
+	}
+	if z.loaded && tz == z.tz && tzSet == z.tzSet && sameStat(info, z.localtimeInfo) {
+		return z.loc, false, nil
+	}
+
+	loc, err := z.load()
+	if err != nil {
+		return z.loc, false, err
+	}
+
+	changed := z.loaded && !sameZone(loc, z.loc, time.Now())
+	z.tz, z.tzSet, z.localtimeInfo, z.loaded = tz, tzSet, info, true
+	z.loc = loc
+	return loc, changed, nil
+}
+
+// sameStat reports whether two stats, nil for a missing file, are of the
+// same unmodified file.
+func sameStat(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return os.SameFile(a, b) && a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+// sameZone reports whether two locations have the same name and offset at t.
+func sameZone(a, b *time.Location, t time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	_, offsetA := t.In(a).Zone()
+	_, offsetB := t.In(b).Zone()
+	return a.String() == b.String() && offsetA == offsetB
+}
+
+// load resolves the location following the same rules as time.Local.
+func (z *zoneSource) load() (*time.Location, error) {
+	if tz, ok := z.lookupEnv("TZ"); ok {
+		// A leading colon is allowed by POSIX and means the same thing
+		tz = strings.TrimPrefix(tz, ":")
+		switch tz {
+		case "", "UTC":
+			return time.UTC, nil
+		}
+		if filepath.IsAbs(tz) {
+			return z.loadFile(filepath.Base(tz), tz)
+		}
+		return z.loadName(tz)
+	}
+
+	if _, err := os.Stat(z.localtime); errors.Is(err, os.ErrNotExist) {
+		// Systems without /etc/localtime (e.g. Windows) keep the process zone
+		return time.Local, nil
+	}
+	return z.loadFile(z.zoneName(), z.localtime)
+}
+
+// loadName loads a zone such as "Europe/Berlin" from the zoneinfo root,
+// falling back to the Go runtime's zone database.
+func (z *zoneSource) loadName(name string) (*time.Location, error) {
+	loc, err := z.loadFile(name, filepath.Join(z.root, filepath.FromSlash(name)))
+	if err == nil {
+		return loc, nil
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc, nil
+	}
+	return nil, err
+}
+
+// loadFile loads TZif data from path under the given zone name.
+func (z *zoneSource) loadFile(name, path string) (*time.Location, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read time zone %q: %w", name, err)
+	}
+
+	loc, err := time.LoadLocationFromTZData(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// zoneName derives the zone name from the /etc/localtime symlink target,
+// e.g. "/usr/share/zoneinfo/Asia/Ho_Chi_Minh" becomes "Asia/Ho_Chi_Minh".
+func (z *zoneSource) zoneName() string {
+	target, err := os.Readlink(z.localtime)
+	if err != nil {
+		return "Local"
+	}
+
+	target = filepath.ToSlash(target)
+	if rel, err := filepath.Rel(z.root, target); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return name
+	}
+	return "Local"
+}
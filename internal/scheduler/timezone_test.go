@@ -0,0 +1,159 @@
+package scheduler
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// writeZone writes a minimal TZif file for a fixed-offset zone.
+func writeZone(t *testing.T, path, abbrev string, offset int32) {
+	t.Helper()
+
+	var buf []byte
+	buf = append(buf, "TZif"...)
+	buf = append(buf, make([]byte, 16)...) // version 1 and reserved bytes
+	// isutcnt, isstdcnt, leapcnt, timecnt, typecnt, charcnt
+	for _, n := range []uint32{0, 0, 0, 0, 1, uint32(len(abbrev) + 1)} {
+		buf = binary.BigEndian.AppendUint32(buf, n)
+	}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(offset))
+	buf = append(buf, 0, 0) // isdst, abbreviation index
+	buf = append(buf, abbrev...)
+	buf = append(buf, 0)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestZone creates a zoneinfo root with two zones and a localtime link.
+func newTestZone(t *testing.T, env map[string]string) *zoneSource {
+	t.Helper()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "zoneinfo")
+	writeZone(t, filepath.Join(root, "Test", "East"), "EST2", 2*3600)
+	writeZone(t, filepath.Join(root, "Test", "West"), "WST5", -5*3600)
+
+	localtime := filepath.Join(dir, "localtime")
+	if err := os.Symlink(filepath.Join(root, "Test", "East"), localtime); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	return &zoneSource{
+		localtime: localtime,
+		root:      root,
+		lookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+	}
+}
+
+// relink points the localtime symlink at another zone.
+func relink(t *testing.T, z *zoneSource, name string) {
+	t.Helper()
+
+	tmp := z.localtime + ".new"
+	if err := os.Symlink(filepath.Join(z.root, name), tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, z.localtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestZoneSource_Localtime(t *testing.T) {
+	z := newTestZone(t, nil)
+
+	loc, changed, err := z.Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if changed {
+		t.Error("first load should not report a change")
+	}
+	if loc.String() != "Test/East" {
+		t.Errorf("expected zone name from symlink target, got %q", loc)
+	}
+
+	if _, changed, _ := z.Check(); changed {
+		t.Error("expected no change without touching localtime")
+	}
+
+	relink(t, z, "Test/West")
+	loc, changed, err = z.Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !changed || loc.String() != "Test/West" {
+		t.Errorf("expected change to Test/West, got %q (changed %v)", loc, changed)
+	}
+}
+
+func TestZoneSource_TZ(t *testing.T) {
+	env := map[string]string{"TZ": "Test/West"}
+	z := newTestZone(t, env)
+
+	loc, _, err := z.Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if _, offset := time.Date(2023, 1, 1, 0, 0, 0, 0, loc).Zone(); offset != -5*3600 {
+		t.Errorf("expected TZ to override localtime, got offset %d", offset)
+	}
+
+	env["TZ"] = ":Test/East"
+	loc, changed, err := z.Check()
+	if err != nil || !changed || loc.String() != "Test/East" {
+		t.Errorf("expected change to Test/East, got %q (changed %v, err %v)", loc, changed, err)
+	}
+
+	env["TZ"] = ""
+	if loc, _, _ := z.Check(); loc != time.UTC {
+		t.Errorf("expected empty TZ to mean UTC, got %q", loc)
+	}
+
+	env["TZ"] = "Test/Nowhere"
+	if _, _, err := z.Check(); err == nil {
+		t.Error("expected error for unknown zone, got nil")
+	}
+}
+
+func TestScheduler_localize_FollowsZoneChange(t *testing.T) {
+	s := New(config.ReminderConfig{Schedule: "at 9am"}, &MockPlayer{}, &MockNotifier{})
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+	s.zone = newTestZone(t, nil)
+
+	// 07:00 UTC is 09:00 in Test/East and 02:00 in Test/West
+	instant := time.Date(2023, 1, 2, 7, 0, 0, 0, time.UTC)
+
+	now := s.localize(instant)
+	if !s.matches(now) {
+		t.Errorf("expected 9am reminder at %s", now.Format("15:04 MST"))
+	}
+
+	relink(t, s.zone, "Test/West")
+	now = s.localize(instant)
+	if now.Hour() != 2 {
+		t.Errorf("expected local time to follow the new zone, got %s", now.Format("15:04 MST"))
+	}
+	if s.matches(now) {
+		t.Errorf("expected no reminder at %s after the zone change", now.Format("15:04 MST"))
+	}
+
+	next := s.upcoming(now, 1)
+	if len(next) != 1 || !next[0].Time.Equal(time.Date(2023, 1, 2, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected next reminder at 9am Test/West (14:00 UTC), got %+v", next)
+	}
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/message"
+)
+
+// minutesPerDay is the number of minutes in a day.
+const minutesPerDay = 24 * 60
+
+// clockRange is a half-open time-of-day range in minutes since midnight.
+// A range whose end is before its start wraps past midnight.
+type clockRange struct {
+	start int
+	end   int
+}
+
+// contains reports whether the minute of day m falls within the range.
+func (r clockRange) contains(m int) bool {
+	if r.start <= r.end {
+		return m >= r.start && m < r.end
+	}
+	return m >= r.start || m < r.end
+}
+
+// variant is a parsed config.VariantConfig.
+type variant struct {
+	name    string
+	window  clockRange
+	message string
+	sound   string
+	volume  float64
+	// template is the parsed message (nil if empty)
+	template *message.Template
+}
+
+// parseClock parses a time of day in "HH:MM" format into minutes since midnight.
+func parseClock(s string) (int, error) {
+	hour, minute, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time of day %q: expected HH:MM", s)
+	}
+
+	h, err := strconv.Atoi(hour)
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+	m, err := strconv.Atoi(minute)
+	if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid minute in %q", s)
+	}
+
+	return h*60 + m, nil
+}
+
+// parseVariants converts the configured variants into their runtime form.
+func parseVariants(cfgs []config.VariantConfig) ([]variant, error) {
+	variants := make([]variant, 0, len(cfgs))
+	for i, c := range cfgs {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("variant %d", i+1)
+		}
+
+		from, err := parseClock(c.From)
+		if err != nil {
+			return nil, fmt.Errorf("variant %q: from: %w", name, err)
+		}
+		to, err := parseClock(c.To)
+		if err != nil {
+			return nil, fmt.Errorf("variant %q: to: %w", name, err)
+		}
+		if from == to%minutesPerDay {
+			return nil, fmt.Errorf("variant %q: empty time range %s-%s", name, c.From, c.To)
+		}
+		if c.Volume < 0 || c.Volume > 1 {
+			return nil, fmt.Errorf("variant %q: volume %v must be between 0.0 and 1.0", name, c.Volume)
+		}
+
+		variants = append(variants, variant{
+			name:    name,
+			window:  clockRange{start: from, end: to},
+			message: c.Message,
+			sound:   c.Sound,
+			volume:  c.Volume,
+		})
+	}
+	return variants, nil
+}
+
+// selectVariant returns the first variant whose range contains t, or nil.
+// Selection only depends on the configured order and the wall-clock time,
+// so the same time always yields the same variant.
+func selectVariant(variants []variant, t time.Time) *variant {
+	m := t.Hour()*60 + t.Minute()
+	for i := range variants {
+		if variants[i].window.contains(m) {
+			return &variants[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+var testVariants = []config.VariantConfig{
+	{Name: "morning", From: "06:00", To: "12:00", Message: "Stretch your back", Sound: "stretch.wav", Volume: 0.5},
+	{Name: "after lunch", From: "13:00", To: "17:00", Message: "Drink some water"},
+	{Name: "evening", From: "17:00", To: "01:00", Message: "Look out the window", Sound: "evening.wav"},
+}
+
+func TestSelectVariant(t *testing.T) {
+	variants, err := parseVariants(testVariants)
+	if err != nil {
+		t.Fatalf("parseVariants() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		hour int
+		min  int
+		want string
+	}{
+		{name: "Morning start is inclusive", hour: 6, min: 0, want: "morning"},
+		{name: "Morning", hour: 9, min: 0, want: "morning"},
+		{name: "Morning end is exclusive", hour: 12, min: 0, want: ""},
+		{name: "After lunch", hour: 14, min: 30, want: "after lunch"},
+		{name: "Evening", hour: 17, min: 0, want: "evening"},
+		{name: "Evening wraps past midnight", hour: 0, min: 30, want: "evening"},
+		{name: "Night uses default", hour: 3, min: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2023, 1, 2, tt.hour, tt.min, 0, 0, time.UTC)
+			got := ""
+			if v := selectVariant(variants, now); v != nil {
+				got = v.name
+			}
+			if got != tt.want {
+				t.Errorf("selectVariant() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectVariant_FirstMatchWins(t *testing.T) {
+	variants, err := parseVariants([]config.VariantConfig{
+		{Name: "first", From: "09:00", To: "18:00"},
+		{Name: "second", From: "12:00", To: "13:00"},
+	})
+	if err != nil {
+		t.Fatalf("parseVariants() error = %v", err)
+	}
+
+	v := selectVariant(variants, time.Date(2023, 1, 2, 12, 30, 0, 0, time.UTC))
+	if v == nil || v.name != "first" {
+		t.Errorf("expected first variant to win, got %+v", v)
+	}
+}
+
+func TestParseVariants_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.VariantConfig
+	}{
+		{name: "Missing colon", cfg: config.VariantConfig{From: "9", To: "12:00"}},
+		{name: "Invalid hour", cfg: config.VariantConfig{From: "25:00", To: "12:00"}},
+		{name: "Invalid minute", cfg: config.VariantConfig{From: "09:00", To: "12:75"}},
+		{name: "Empty range", cfg: config.VariantConfig{From: "09:00", To: "09:00"}},
+		{name: "Too loud", cfg: config.VariantConfig{From: "09:00", To: "12:00", Volume: 1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseVariants([]config.VariantConfig{tt.cfg}); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestScheduler_Next_Variants(t *testing.T) {
+	cfg := config.ReminderConfig{Interval: "4h", TriggerMinutes: []int{0}, Variants: testVariants}
+	s := New(cfg, &MockPlayer{}, &MockNotifier{})
+
+	from := time.Date(2023, 1, 2, 8, 30, 0, 0, time.UTC)
+	triggers, err := s.Next(from, 3)
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if len(triggers) != 3 {
+		t.Fatalf("expected 3 triggers, got %d", len(triggers))
+	}
+
+	want := []struct {
+		hour    int
+		variant string
+		sound   string
+	}{
+		{hour: 9, variant: "morning", sound: "stretch.wav"},
+		{hour: 10, variant: "morning", sound: "stretch.wav"},
+		{hour: 11, variant: "morning", sound: "stretch.wav"},
+	}
+	for i, w := range want {
+		if triggers[i].Time.Hour() != w.hour || triggers[i].Variant != w.variant || triggers[i].Sound != w.sound {
+			t.Errorf("trigger %d = %+v, want hour %d variant %q sound %q", i, triggers[i], w.hour, w.variant, w.sound)
+		}
+	}
+}
+
+func TestScheduler_Simulate_Deterministic(t *testing.T) {
+	cfg := config.ReminderConfig{Interval: "1h", Variants: testVariants}
+	s := New(cfg, &MockPlayer{}, &MockNotifier{})
+
+	day := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	first, err := s.Simulate(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	second, err := s.Simulate(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+
+	if len(first) != 24 {
+		t.Fatalf("expected 24 hourly triggers, got %d", len(first))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("trigger %d differs between runs: %+v vs %+v", i, first[i], second[i])
+		}
+	}
+	if first[17].Message != "Look out the window" {
+		t.Errorf("expected evening message at 17:00, got %q", first[17].Message)
+	}
+}
+
+func TestScheduler_Trigger_UsesVariant(t *testing.T) {
+	player := &MockPlayer{}
+	notifier := &MockNotifier{}
+	s := New(config.ReminderConfig{Interval: "30m", Variants: testVariants}, player, notifier)
+	if err := s.prepare(); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	s.trigger(context.Background(), time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC))
+
+	if player.LastFile != "stretch.wav" {
+		t.Errorf("expected variant sound, got %q", player.LastFile)
+	}
+	if player.LastVolume != 0.5 {
+		t.Errorf("expected variant volume, got %v", player.LastVolume)
+	}
+	if notifier.LastMessage != "Stretch your back" {
+		t.Errorf("expected variant message, got %q", notifier.LastMessage)
+	}
+}
@@ -6,10 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 
-	"github.com/hoangtran1411/rest-time-reminder-go/internal/audio"
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/app"
 	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
-	"github.com/hoangtran1411/rest-time-reminder-go/internal/notification"
-	"github.com/hoangtran1411/rest-time-reminder-go/internal/scheduler"
 	"github.com/kardianos/service"
 )
 
@@ -138,13 +136,16 @@ func (p *program) Start(_ service.Service) error {
 	p.cancel = cancel
 
 	// Initialize components
-	player := audio.NewPlayer(p.cfg.Sound)
-	notifier := notification.NewNotifier(p.cfg.Notification)
-	sched := scheduler.New(p.cfg.Reminder, player, notifier)
+	sched, player, err := app.Build(p.cfg)
+	if err != nil {
+		cancel()
+		return err
+	}
 
 	// Start scheduler in background
 	go func() {
 		defer close(p.doneChan)
+		defer func() { _ = player.Close() }()
 		if err := sched.Run(ctx); err != nil {
 			slog.Error("scheduler error", "error", err)
 		}
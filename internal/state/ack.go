@@ -0,0 +1,29 @@
+package state
+
+import "time"
+
+// ackFile is the name of the file holding the last acknowledgement.
+const ackFile = "ack.json"
+
+// ack is the on-disk layout of ackFile.
+type ack struct {
+	At time.Time `json:"at"`
+}
+
+// Acknowledge records that the user responded to the reminders up to now.
+func (s *Store) Acknowledge(now time.Time) error {
+	return s.update(func() error { return s.writeJSON(ackFile, &ack{At: now}) })
+}
+
+// LastAck returns when reminders were last acknowledged, or the zero time
+// if they never were.
+func (s *Store) LastAck() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var a ack
+	if err := s.readJSON(ackFile, &a); err != nil {
+		return time.Time{}, err
+	}
+	return a.At, nil
+}
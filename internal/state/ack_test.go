@@ -0,0 +1,29 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore_Acknowledge(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if at, err := store.LastAck(); err != nil || !at.IsZero() {
+		t.Fatalf("expected no acknowledgement, got %v (err %v)", at, err)
+	}
+
+	now := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+	if err := store.Acknowledge(now); err != nil {
+		t.Fatalf("Acknowledge() error = %v", err)
+	}
+	if err := store.Acknowledge(now.Add(time.Hour)); err != nil {
+		t.Fatalf("Acknowledge() error = %v", err)
+	}
+
+	if at, err := store.LastAck(); err != nil || !at.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected the latest acknowledgement at 11:00, got %v (err %v)", at, err)
+	}
+}
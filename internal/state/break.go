@@ -0,0 +1,56 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// breakFile is the name of the file holding the break in progress.
+const breakFile = "break.json"
+
+// Break is a break during which the ambient soundtrack plays.
+type Break struct {
+	// Start is when the break started
+	Start time.Time `json:"start"`
+	// End is when the break finishes
+	End time.Time `json:"end"`
+}
+
+// Remaining returns the time left in the break at now, or zero once it ended.
+func (b Break) Remaining(now time.Time) time.Duration {
+	if d := b.End.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// StartBreak records a break of duration d, replacing any previous one.
+func (s *Store) StartBreak(now time.Time, d time.Duration) (Break, error) {
+	if d <= 0 {
+		return Break{}, fmt.Errorf("break duration must be positive, got %s", d)
+	}
+
+	b := Break{Start: now, End: now.Add(d)}
+	if err := s.update(func() error { return s.writeJSON(breakFile, &b) }); err != nil {
+		return Break{}, err
+	}
+	return b, nil
+}
+
+// Break returns the break in progress, or nil if there is none.
+func (s *Store) Break() (*Break, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b *Break
+	if err := s.readJSON(breakFile, &b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// StopBreak ends the break in progress. Stopping when there is no break
+// is not an error.
+func (s *Store) StopBreak() error {
+	return s.update(func() error { return s.remove(breakFile) })
+}
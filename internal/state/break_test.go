@@ -0,0 +1,43 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore_Break_Lifecycle(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if b, err := store.Break(); err != nil || b != nil {
+		t.Fatalf("expected no break, got %+v (err %v)", b, err)
+	}
+
+	now := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+	if _, err := store.StartBreak(now, 5*time.Minute); err != nil {
+		t.Fatalf("StartBreak() error = %v", err)
+	}
+
+	b, err := store.Break()
+	if err != nil || b == nil {
+		t.Fatalf("expected a break in progress, got %+v (err %v)", b, err)
+	}
+	if got := b.Remaining(now.Add(2 * time.Minute)); got != 3*time.Minute {
+		t.Errorf("Remaining() = %v, want 3m", got)
+	}
+
+	if err := store.StopBreak(); err != nil {
+		t.Fatalf("StopBreak() error = %v", err)
+	}
+	if err := store.StopBreak(); err != nil {
+		t.Errorf("StopBreak() without break error = %v", err)
+	}
+	if b, _ := store.Break(); b != nil {
+		t.Errorf("expected break to be cleared, got %+v", b)
+	}
+	if _, err := store.StartBreak(now, 0); err == nil {
+		t.Error("expected error for zero duration, got nil")
+	}
+}
@@ -0,0 +1,74 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// focusFile is the name of the file holding the active focus session.
+const focusFile = "focus.json"
+
+// Focus is a focus session during which regular reminders are suppressed.
+type Focus struct {
+	// Start is when the session started
+	Start time.Time `json:"start"`
+	// End is when the session finishes
+	End time.Time `json:"end"`
+}
+
+// Remaining returns the time left in the session at now, or zero once it ended.
+func (f Focus) Remaining(now time.Time) time.Duration {
+	if d := f.End.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// StartFocus starts a focus session of duration d, replacing any active one.
+func (s *Store) StartFocus(now time.Time, d time.Duration) (Focus, error) {
+	if d <= 0 {
+		return Focus{}, fmt.Errorf("focus duration must be positive, got %s", d)
+	}
+
+	f := Focus{Start: now, End: now.Add(d)}
+	if err := s.update(func() error { return s.writeJSON(focusFile, &f) }); err != nil {
+		return Focus{}, err
+	}
+	return f, nil
+}
+
+// Focus returns the current focus session, or nil if none is active.
+func (s *Store) Focus() (*Focus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var f *Focus
+	if err := s.readJSON(focusFile, &f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// StopFocus ends the current focus session. Stopping when no session
+// is active is not an error.
+func (s *Store) StopFocus() error {
+	return s.update(func() error { return s.remove(focusFile) })
+}
+
+// FinishFocus ends the session f once it is over, unless it was stopped or
+// replaced by a new session since it was read. It reports whether f ended.
+func (s *Store) FinishFocus(f Focus) (bool, error) {
+	ended := false
+	err := s.update(func() error {
+		var current *Focus
+		if err := s.readJSON(focusFile, &current); err != nil {
+			return err
+		}
+		if current == nil || !current.Start.Equal(f.Start) || !current.End.Equal(f.End) {
+			return nil
+		}
+		ended = true
+		return s.remove(focusFile)
+	})
+	return ended, err
+}
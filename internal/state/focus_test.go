@@ -0,0 +1,93 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore_Focus_Lifecycle(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if f, err := store.Focus(); err != nil || f != nil {
+		t.Fatalf("expected no focus session, got %+v (err %v)", f, err)
+	}
+
+	now := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+	if _, err := store.StartFocus(now, 50*time.Minute); err != nil {
+		t.Fatalf("StartFocus() error = %v", err)
+	}
+
+	f, err := store.Focus()
+	if err != nil || f == nil {
+		t.Fatalf("expected active focus session, got %+v (err %v)", f, err)
+	}
+	if got := f.Remaining(now.Add(20 * time.Minute)); got != 30*time.Minute {
+		t.Errorf("Remaining() = %v, want 30m", got)
+	}
+	if got := f.Remaining(now.Add(time.Hour)); got != 0 {
+		t.Errorf("Remaining() after end = %v, want 0", got)
+	}
+
+	if err := store.StopFocus(); err != nil {
+		t.Fatalf("StopFocus() error = %v", err)
+	}
+	if err := store.StopFocus(); err != nil {
+		t.Errorf("StopFocus() without session error = %v", err)
+	}
+	if f, _ := store.Focus(); f != nil {
+		t.Errorf("expected focus session to be cleared, got %+v", f)
+	}
+}
+
+func TestStore_StartFocus_InvalidDuration(t *testing.T) {
+	store, _ := New(t.TempDir())
+
+	if _, err := store.StartFocus(time.Now(), 0); err == nil {
+		t.Error("expected error for zero duration, got nil")
+	}
+}
+
+func TestStore_FinishFocus(t *testing.T) {
+	store, _ := New(t.TempDir())
+	now := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	read := func() Focus {
+		t.Helper()
+		f, err := store.Focus()
+		if err != nil || f == nil {
+			t.Fatalf("expected a focus session, got %+v (err %v)", f, err)
+		}
+		return *f
+	}
+
+	// A session started after the expired one was read is kept
+	if _, err := store.StartFocus(now, 50*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	expired := read()
+	if _, err := store.StartFocus(now.Add(50*time.Minute), 25*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if ended, err := store.FinishFocus(expired); err != nil || ended {
+		t.Errorf("FinishFocus() of a replaced session = %v, %v, want false", ended, err)
+	}
+	if f := read(); !f.End.Equal(now.Add(75 * time.Minute)) {
+		t.Errorf("expected the new session to be kept, got %+v", f)
+	}
+
+	// The session that was read is ended
+	if ended, err := store.FinishFocus(read()); err != nil || !ended {
+		t.Errorf("FinishFocus() = %v, %v, want true", ended, err)
+	}
+	if f, _ := store.Focus(); f != nil {
+		t.Errorf("expected focus session to be cleared, got %+v", f)
+	}
+
+	// A stopped session is not ended again
+	if ended, err := store.FinishFocus(expired); err != nil || ended {
+		t.Errorf("FinishFocus() without session = %v, %v, want false", ended, err)
+	}
+}
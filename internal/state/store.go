@@ -0,0 +1,171 @@
+// Package state persists runtime state shared between CLI commands
+// and the running reminder instance.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNotFound is returned when a requested item does not exist.
+var ErrNotFound = errors.New("not found")
+
+// lockName is the file locked while a state file is read and rewritten.
+const lockName = "state.lock"
+
+// Store reads and writes state files in a directory.
+type Store struct {
+	dir string
+	// mu serializes access within the process; update also locks lockName
+	// against the CLI and the running instance
+	mu sync.Mutex
+	// files are the state files as last read, guarded by mu
+	files map[string]cachedFile
+}
+
+// cachedFile is the content of a state file as last read.
+type cachedFile struct {
+	info os.FileInfo
+	data []byte
+}
+
+// New creates a Store rooted at dir.
+// An empty dir uses $HOME/.rest-time-reminder.
+func New(dir string) (*Store, error) {
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".rest-time-reminder")
+	}
+
+	return &Store{dir: dir, files: make(map[string]cachedFile)}, nil
+}
+
+// Dir returns the directory the store writes to.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// update runs fn, which reads and rewrites state files, while holding a
+// lock shared with other processes using the same directory, so changes
+// made at the same time are not lost.
+func (s *Store) update(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, lockName), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open state lock: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("failed to lock state: %w", err)
+	}
+	defer func() { _ = unlockFile(f) }()
+
+	return fn()
+}
+
+// readJSON decodes the named state file into v.
+// A missing file leaves v untouched and is not an error.
+func (s *Store) readJSON(name string, v any) error {
+	data, ok, err := s.readFile(name)
+	if err != nil || !ok {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// readFile returns the content of the named state file, reporting whether
+// it exists. The running instance polls state files every second, so the
+// content is kept and the file only read again once it was replaced or
+// modified. The caller must hold s.mu.
+func (s *Store) readFile(name string) ([]byte, bool, error) {
+	path := filepath.Join(s.dir, name)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		delete(s.files, name)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if f, ok := s.files[name]; ok && unchanged(f.info, info) {
+		return f.data, true, nil
+	}
+
+	// A file replaced after the stat is read again on the next call, as its
+	// stat then no longer matches
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		delete(s.files, name)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	s.files[name] = cachedFile{info: info, data: data}
+	return data, true, nil
+}
+
+// unchanged reports whether two stats are of the same, unmodified file.
+// State files are replaced rather than rewritten, so a new version is also
+// a new file.
+func unchanged(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+// remove deletes the named state file. A missing file is not an error.
+func (s *Store) remove(name string) error {
+	err := os.Remove(filepath.Join(s.dir, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeJSON atomically replaces the named state file with v.
+func (s *Store) writeJSON(name string, v any) error {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	// Write to a temporary file first so readers never see a partial file
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_readFile(t *testing.T) {
+	dir := t.TempDir()
+	daemon, _ := New(dir)
+	cli, _ := New(dir)
+	now := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	end := func() time.Time {
+		t.Helper()
+		f, err := daemon.Focus()
+		if err != nil || f == nil {
+			t.Fatalf("expected a focus session, got %+v (err %v)", f, err)
+		}
+		return f.End
+	}
+
+	if _, err := cli.StartFocus(now, 50*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	first := end()
+
+	// An unchanged file is not read again: rewriting it in place with the
+	// same size and time goes unnoticed
+	path := filepath.Join(dir, focusFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := bytes.Replace(data, []byte("10:50"), []byte("10:55"), 1)
+	if err := os.WriteFile(path, edited, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if got := end(); !got.Equal(first) {
+		t.Errorf("expected the cached session, got end %v", got)
+	}
+
+	// A session replaced by another process is read again
+	if _, err := cli.StartFocus(now, 25*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got := end(); !got.Equal(now.Add(25 * time.Minute)) {
+		t.Errorf("expected the new session, got end %v", got)
+	}
+
+	if err := cli.StopFocus(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := daemon.Focus(); err != nil || f != nil {
+		t.Errorf("expected no focus session, got %+v (err %v)", f, err)
+	}
+}
@@ -0,0 +1,155 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// timersFile is the name of the file holding one-off reminders.
+const timersFile = "timers.json"
+
+// Timer is a one-off reminder scheduled from the CLI.
+type Timer struct {
+	// ID identifies the timer for cancellation
+	ID string `json:"id"`
+	// At is when the timer fires
+	At time.Time `json:"at"`
+	// Message is the notification message (empty for the configured message)
+	Message string `json:"message,omitempty"`
+	// Created is when the timer was scheduled
+	Created time.Time `json:"created"`
+}
+
+// timerFile is the on-disk layout of timersFile.
+type timerFile struct {
+	NextID int     `json:"next_id"`
+	Timers []Timer `json:"timers"`
+}
+
+// AddTimer schedules a one-off reminder at the given time.
+func (s *Store) AddTimer(at time.Time, message string) (Timer, error) {
+	var t Timer
+	err := s.update(func() error {
+		var f timerFile
+		if err := s.readJSON(timersFile, &f); err != nil {
+			return err
+		}
+
+		f.NextID++
+		t = Timer{
+			ID:      strconv.Itoa(f.NextID),
+			At:      at,
+			Message: message,
+			Created: time.Now(),
+		}
+		f.Timers = append(f.Timers, t)
+		return s.writeJSON(timersFile, &f)
+	})
+	if err != nil {
+		return Timer{}, err
+	}
+	return t, nil
+}
+
+// Timers returns all pending timers ordered by firing time.
+func (s *Store) Timers() ([]Timer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var f timerFile
+	if err := s.readJSON(timersFile, &f); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(f.Timers, func(i, j int) bool { return f.Timers[i].At.Before(f.Timers[j].At) })
+	return f.Timers, nil
+}
+
+// CancelTimer removes the timer with the given ID.
+func (s *Store) CancelTimer(id string) error {
+	return s.update(func() error {
+		var f timerFile
+		if err := s.readJSON(timersFile, &f); err != nil {
+			return err
+		}
+
+		for i, t := range f.Timers {
+			if t.ID == id {
+				f.Timers = append(f.Timers[:i], f.Timers[i+1:]...)
+				return s.writeJSON(timersFile, &f)
+			}
+		}
+		return fmt.Errorf("timer %q: %w", id, ErrNotFound)
+	})
+}
+
+// TakeDueTimers removes and returns every timer due at or before now.
+func (s *Store) TakeDueTimers(now time.Time) ([]Timer, error) {
+	// This runs on every tick and rarely finds a timer due, so look before
+	// taking the lock shared with the CLI
+	timers, err := s.Timers()
+	if err != nil {
+		return nil, err
+	}
+	if len(timers) == 0 || timers[0].At.After(now) {
+		return nil, nil
+	}
+
+	var due []Timer
+	err = s.update(func() error {
+		var f timerFile
+		if err := s.readJSON(timersFile, &f); err != nil {
+			return err
+		}
+
+		var pending []Timer
+		for _, t := range f.Timers {
+			if t.At.After(now) {
+				pending = append(pending, t)
+			} else {
+				due = append(due, t)
+			}
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		f.Timers = pending
+		return s.writeJSON(timersFile, &f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+// ParseWhen resolves a CLI time specification relative to now.
+// kind is "in" with a duration (e.g., "10m") or "at" with a time of day
+// (e.g., "15:30"); a time of day that has already passed means tomorrow.
+func ParseWhen(kind, value string, now time.Time) (time.Time, error) {
+	switch kind {
+	case "in":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("duration must be positive, got %s", value)
+		}
+		return now.Add(d), nil
+	case "at":
+		clock, err := time.Parse("15:04", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time of day %q: expected HH:MM", value)
+		}
+		at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown time specification %q: use \"in\" or \"at\"", kind)
+	}
+}
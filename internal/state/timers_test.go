@@ -0,0 +1,135 @@
+package state
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStore_Timers_Lifecycle(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	now := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+	oven, err := store.AddTimer(now.Add(10*time.Minute), "check the oven")
+	if err != nil {
+		t.Fatalf("AddTimer() error = %v", err)
+	}
+	meeting, err := store.AddTimer(now.Add(5*time.Minute), "stand-up")
+	if err != nil {
+		t.Fatalf("AddTimer() error = %v", err)
+	}
+	if oven.ID == meeting.ID {
+		t.Fatalf("expected unique IDs, got %q twice", oven.ID)
+	}
+
+	// A fresh store on the same directory sees the persisted timers
+	reopened, _ := New(dir)
+	timers, err := reopened.Timers()
+	if err != nil {
+		t.Fatalf("Timers() error = %v", err)
+	}
+	if len(timers) != 2 || timers[0].ID != meeting.ID {
+		t.Fatalf("expected timers ordered by time starting with %q, got %+v", meeting.ID, timers)
+	}
+
+	due, err := reopened.TakeDueTimers(now.Add(7 * time.Minute))
+	if err != nil {
+		t.Fatalf("TakeDueTimers() error = %v", err)
+	}
+	if len(due) != 1 || due[0].Message != "stand-up" {
+		t.Fatalf("expected only the stand-up timer to be due, got %+v", due)
+	}
+
+	if err := store.CancelTimer(oven.ID); err != nil {
+		t.Fatalf("CancelTimer() error = %v", err)
+	}
+	if timers, _ := store.Timers(); len(timers) != 0 {
+		t.Errorf("expected no timers left, got %+v", timers)
+	}
+}
+
+func TestStore_Timers_Concurrent(t *testing.T) {
+	// Two stores on one directory stand in for the CLI and the running
+	// instance: only the file lock keeps them from losing each other's timers
+	dir := t.TempDir()
+	cli, _ := New(dir)
+	daemon, _ := New(dir)
+	at := time.Now().Add(time.Hour)
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for range 50 {
+			if _, err := cli.AddTimer(at, ""); err != nil {
+				t.Errorf("AddTimer() error = %v", err)
+			}
+		}
+	})
+	wg.Go(func() {
+		for range 50 {
+			if _, err := daemon.AddTimer(at, ""); err != nil {
+				t.Errorf("AddTimer() error = %v", err)
+			}
+			if _, err := daemon.TakeDueTimers(time.Now()); err != nil {
+				t.Errorf("TakeDueTimers() error = %v", err)
+			}
+		}
+	})
+	wg.Wait()
+
+	timers, err := cli.Timers()
+	if err != nil {
+		t.Fatalf("Timers() error = %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, timer := range timers {
+		ids[timer.ID] = true
+	}
+	if len(timers) != 100 || len(ids) != 100 {
+		t.Errorf("expected 100 timers with unique IDs, got %d with %d IDs", len(timers), len(ids))
+	}
+}
+
+func TestStore_CancelTimer_NotFound(t *testing.T) {
+	store, _ := New(t.TempDir())
+
+	err := store.CancelTimer("42")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestParseWhen(t *testing.T) {
+	now := time.Date(2023, 1, 2, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		kind    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "In duration", kind: "in", value: "10m", want: now.Add(10 * time.Minute)},
+		{name: "At later today", kind: "at", value: "15:30", want: time.Date(2023, 1, 2, 15, 30, 0, 0, time.UTC)},
+		{name: "At earlier means tomorrow", kind: "at", value: "09:00", want: time.Date(2023, 1, 3, 9, 0, 0, 0, time.UTC)},
+		{name: "Negative duration", kind: "in", value: "-5m", wantErr: true},
+		{name: "Invalid time", kind: "at", value: "25:00", wantErr: true},
+		{name: "Unknown kind", kind: "on", value: "monday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWhen(tt.kind, tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWhen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseWhen() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package tips
+
+import "time"
+
+// Categories of the built-in tips.
+const (
+	categoryEyes    = "eyes"
+	categoryPosture = "posture"
+	categoryStretch = "stretch"
+	categoryMove    = "move"
+)
+
+// builtin are the tips available without any files.
+var builtin = []Tip{
+	// Eyes
+	{Category: categoryEyes, Duration: 20 * time.Second, Text: "Look at something at least 6 meters (20 feet) away for 20 seconds."},
+	{Category: categoryEyes, Duration: 15 * time.Second, Text: "Close your eyes and relax them for a few breaths."},
+	{Category: categoryEyes, Duration: 20 * time.Second, Text: "Blink slowly 10 times to rewet your eyes."},
+	{Category: categoryEyes, Duration: 30 * time.Second, Text: "Roll your eyes in slow circles, 5 times each way."},
+	{Category: categoryEyes, Duration: time.Minute, Text: "Rub your palms together until warm and rest them over your closed eyes."},
+	{Category: categoryEyes, Duration: 30 * time.Second, Text: "Focus on your fingertip at arm's length, then on something far away. Switch 10 times."},
+
+	// Posture
+	{Category: categoryPosture, Duration: 15 * time.Second, Text: "Roll your shoulders backwards 10 times and let them drop."},
+	{Category: categoryPosture, Duration: 20 * time.Second, Text: "Tuck your chin in to make a double chin, hold for 5 seconds and repeat 3 times."},
+	{Category: categoryPosture, Duration: 30 * time.Second, Text: "Check your setup: top of the screen at eye level, feet flat, elbows at 90 degrees."},
+	{Category: categoryPosture, Duration: 30 * time.Second, Text: "Squeeze your shoulder blades together for 5 seconds, 5 times."},
+	{Category: categoryPosture, Duration: time.Minute, Text: "Stand with your back against a wall, heels, hips and head touching, for a minute."},
+
+	// Stretching
+	{Category: categoryStretch, Duration: 30 * time.Second, Text: "Tilt your head towards each shoulder and hold for 15 seconds per side."},
+	{Category: categoryStretch, Duration: 30 * time.Second, Text: "Stretch your arms overhead, interlace your fingers and reach up for 15 seconds."},
+	{Category: categoryStretch, Duration: 30 * time.Second, Text: "Stretch your wrists: hold your fingers back with the other hand for 15 seconds per side."},
+	{Category: categoryStretch, Duration: time.Minute, Text: "Sit tall and twist gently to each side, holding for 20 seconds."},
+	{Category: categoryStretch, Duration: time.Minute, Text: "Stand up, hold the back of your chair and stretch your calves, 20 seconds per leg."},
+	{Category: categoryStretch, Duration: 2 * time.Minute, Text: "Bend forward slowly and let your arms hang towards the floor, then roll back up one vertebra at a time."},
+	{Category: categoryStretch, Duration: 2 * time.Minute, Text: "Stretch your chest in a doorway: forearms on the frame, lean forward for 30 seconds, twice."},
+
+	// Moving around
+	{Category: categoryMove, Duration: time.Minute, Text: "Stand up and refill your water glass."},
+	{Category: categoryMove, Duration: 2 * time.Minute, Text: "Do 10 slow squats, then shake out your legs."},
+	{Category: categoryMove, Duration: 3 * time.Minute, Text: "Walk around the room or up and down the stairs."},
+	{Category: categoryMove, Duration: 5 * time.Minute, Text: "Step outside or open a window and take a few deep breaths of fresh air."},
+	{Category: categoryMove, Duration: 10 * time.Minute, Text: "Go for a short walk without your phone."},
+}
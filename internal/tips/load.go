@@ -0,0 +1,121 @@
+package tips
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFile reads tips from a YAML or Markdown file, chosen by extension.
+func LoadFile(path string) ([]Tip, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tips: %w", err)
+	}
+
+	var tips []Tip
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		tips, err = parseYAML(data)
+	case ".md", ".markdown":
+		tips, err = parseMarkdown(data)
+	default:
+		return nil, fmt.Errorf("tips file %s: unsupported format, must be .yaml, .yml or .md", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tips file %s: %w", path, err)
+	}
+	if len(tips) == 0 {
+		return nil, fmt.Errorf("tips file %s: no tips found", path)
+	}
+	return tips, nil
+}
+
+// yamlTip is a tip as written in a YAML file.
+type yamlTip struct {
+	Text     string `yaml:"text"`
+	Category string `yaml:"category"`
+	Duration string `yaml:"duration"`
+}
+
+// parseYAML reads a list of tips:
+//
+//   - text: Roll your shoulders backwards 10 times.
+//     category: posture
+//     duration: 30s
+func parseYAML(data []byte) ([]Tip, error) {
+	var entries []yamlTip
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	tips := make([]Tip, 0, len(entries))
+	for i, e := range entries {
+		text := strings.TrimSpace(e.Text)
+		if text == "" {
+			return nil, fmt.Errorf("tip %d has no text", i+1)
+		}
+		tip := Tip{Text: text, Category: strings.ToLower(strings.TrimSpace(e.Category))}
+		if e.Duration != "" {
+			d, err := time.ParseDuration(e.Duration)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("tip %d: invalid duration %q", i+1, e.Duration)
+			}
+			tip.Duration = d
+		}
+		tips = append(tips, tip)
+	}
+	return tips, nil
+}
+
+// markdownDuration matches a duration in parentheses at the end of a tip.
+var markdownDuration = regexp.MustCompile(`\s*\(([0-9][0-9a-z.]*)\)$`)
+
+// parseMarkdown reads every list item as a tip, in the category of the
+// heading above it. A duration may follow in parentheses:
+//
+//	## Eyes
+//	- Look out of the window for 20 seconds. (20s)
+func parseMarkdown(data []byte) ([]Tip, error) {
+	var tips []Tip
+	category := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if heading, ok := strings.CutPrefix(text, "#"); ok {
+			category = strings.ToLower(strings.TrimSpace(strings.TrimLeft(heading, "#")))
+			continue
+		}
+
+		item, ok := strings.CutPrefix(text, "- ")
+		if !ok {
+			if item, ok = strings.CutPrefix(text, "* "); !ok {
+				continue
+			}
+		}
+		tip := Tip{Text: strings.TrimSpace(item), Category: category}
+		if m := markdownDuration.FindStringSubmatch(tip.Text); m != nil {
+			d, err := time.ParseDuration(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid duration %q", line, m[1])
+			}
+			tip.Duration = d
+			tip.Text = strings.TrimSuffix(tip.Text, m[0])
+		}
+		if tip.Text == "" {
+			return nil, fmt.Errorf("line %d: empty tip", line)
+		}
+		tips = append(tips, tip)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tips, nil
+}
@@ -0,0 +1,82 @@
+package tips
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []Tip
+		wantErr bool
+	}{
+		{
+			name: "YAML",
+			file: "tips.yaml",
+			content: `
+- text: Roll your shoulders backwards 10 times.
+  category: Posture
+  duration: 30s
+- text: Smile.
+`,
+			want: []Tip{
+				{Text: "Roll your shoulders backwards 10 times.", Category: "posture", Duration: 30 * time.Second},
+				{Text: "Smile."},
+			},
+		},
+		{
+			name: "Markdown",
+			file: "tips.md",
+			content: `# My tips
+
+Some notes that are not tips.
+
+## Eyes
+- Look out of the window. (20s)
+* Blink (slowly) 10 times.
+
+## Move
+- Walk to the kitchen and back. (2m)
+`,
+			want: []Tip{
+				{Text: "Look out of the window.", Category: "eyes", Duration: 20 * time.Second},
+				{Text: "Blink (slowly) 10 times.", Category: "eyes"},
+				{Text: "Walk to the kitchen and back.", Category: "move", Duration: 2 * time.Minute},
+			},
+		},
+		{name: "YAML without text", file: "tips.yml", content: "- category: eyes\n", wantErr: true},
+		{name: "YAML invalid duration", file: "tips.yml", content: "- text: Blink.\n  duration: soon\n", wantErr: true},
+		{name: "Markdown invalid duration", file: "tips.md", content: "- Blink. (2x)\n", wantErr: true},
+		{name: "Empty", file: "tips.md", content: "# Nothing here\n", wantErr: true},
+		{name: "Unsupported", file: "tips.txt", content: "- Blink.\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), tt.file, tt.content)
+			got, err := LoadFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
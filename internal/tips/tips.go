@@ -0,0 +1,134 @@
+// Package tips suggests something concrete to do during a break: eye
+// exercises, posture checks, stretches and the like.
+package tips
+
+import (
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+// Tip is a suggestion for a break.
+type Tip struct {
+	// Text is the suggestion itself
+	Text string
+	// Category groups tips, e.g. "eyes" or "stretch"
+	Category string
+	// Duration is how long the tip takes (zero fits any break)
+	Duration time.Duration
+}
+
+// Pool picks tips that fit a break, without repeating one the same day.
+type Pool struct {
+	tips []Tip
+	mu   sync.Mutex
+	rand *rand.Rand
+	// day is the date shown refers to
+	day   string
+	shown map[int]bool
+}
+
+// Option configures optional Pool behavior.
+type Option func(*Pool)
+
+// WithRand sets the random source used to pick tips.
+func WithRand(r *rand.Rand) Option {
+	return func(p *Pool) {
+		p.rand = r
+	}
+}
+
+// New creates a pool of the built-in tips and those in the configured
+// files, limited to the configured categories. Files that cannot be read
+// are skipped and reported in the returned error, along with the pool of
+// the remaining tips.
+func New(cfg config.TipsConfig, opts ...Option) (*Pool, error) {
+	p := &Pool{
+		rand:  rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		shown: make(map[int]bool),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	var all []Tip
+	if cfg.Builtin {
+		all = append(all, builtin...)
+	}
+	var errs []error
+	for _, file := range cfg.Files {
+		tips, err := LoadFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		all = append(all, tips...)
+	}
+
+	for _, tip := range all {
+		if len(cfg.Categories) == 0 || slices.Contains(cfg.Categories, tip.Category) {
+			p.tips = append(p.tips, tip)
+		}
+	}
+	if len(p.tips) == 0 {
+		errs = append(errs, errors.New("no tips to suggest"))
+	}
+	return p, errors.Join(errs...)
+}
+
+// Len returns the number of tips in the pool.
+func (p *Pool) Len() int {
+	return len(p.tips)
+}
+
+// Pick returns a random tip that fits in a break of length d, preferring
+// ones not shown yet on the day of now. Once every tip that fits was shown,
+// they are picked again. It returns false if no tip fits.
+func (p *Pool) Pick(now time.Time, d time.Duration) (Tip, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if day := now.Format(time.DateOnly); day != p.day {
+		p.day = day
+		clear(p.shown)
+	}
+
+	var fits, fresh []int
+	for i, tip := range p.tips {
+		if tip.Duration <= d {
+			fits = append(fits, i)
+			if !p.shown[i] {
+				fresh = append(fresh, i)
+			}
+		}
+	}
+	if len(fits) == 0 {
+		return Tip{}, false
+	}
+	if len(fresh) == 0 {
+		slog.Debug("every tip that fits the break was shown today, starting over", "tips", len(fits))
+		for _, i := range fits {
+			delete(p.shown, i)
+		}
+		fresh = fits
+	}
+
+	i := fresh[p.rand.IntN(len(fresh))]
+	p.shown[i] = true
+	return p.tips[i], true
+}
+
+// Suggest returns the text of a tip that fits in a break of length d, or
+// "" if none does.
+func (p *Pool) Suggest(now time.Time, d time.Duration) string {
+	tip, ok := p.Pick(now, d)
+	if !ok {
+		return ""
+	}
+	return tip.Text
+}
@@ -0,0 +1,115 @@
+package tips
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/hoangtran1411/rest-time-reminder-go/internal/config"
+)
+
+func TestPool_Pick(t *testing.T) {
+	p := &Pool{
+		tips: []Tip{
+			{Text: "blink", Duration: 10 * time.Second},
+			{Text: "look away", Duration: 20 * time.Second},
+			{Text: "stretch", Duration: 2 * time.Minute},
+			{Text: "walk", Duration: 10 * time.Minute},
+		},
+		rand:  rand.New(rand.NewPCG(1, 2)),
+		shown: make(map[int]bool),
+	}
+	day := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// Only tips that fit the break are picked, each once before any repeats
+	seen := make(map[string]int)
+	for range 3 {
+		tip, ok := p.Pick(day, 5*time.Minute)
+		if !ok {
+			t.Fatal("expected a tip")
+		}
+		seen[tip.Text]++
+	}
+	if len(seen) != 3 || seen["walk"] != 0 {
+		t.Errorf("expected each short tip once, got %v", seen)
+	}
+
+	// A longer break still gets the tip it hasn't seen
+	if tip, _ := p.Pick(day, time.Hour); tip.Text != "walk" {
+		t.Errorf("expected the only tip not shown today, got %q", tip.Text)
+	}
+
+	// Once they were all shown, they come round again
+	if _, ok := p.Pick(day, 5*time.Minute); !ok {
+		t.Error("expected tips to repeat once all were shown")
+	}
+
+	// Nothing fits a break shorter than every tip
+	if tip, ok := p.Pick(day, 5*time.Second); ok {
+		t.Errorf("expected no tip, got %q", tip.Text)
+	}
+}
+
+func TestPool_Pick_NewDay(t *testing.T) {
+	p := &Pool{
+		tips:  []Tip{{Text: "blink"}, {Text: "stretch"}},
+		rand:  rand.New(rand.NewPCG(1, 2)),
+		shown: make(map[int]bool),
+	}
+	day := time.Date(2024, 1, 1, 23, 50, 0, 0, time.UTC)
+
+	first, _ := p.Pick(day, time.Minute)
+	second, _ := p.Pick(day.Add(5*time.Minute), time.Minute)
+	if first.Text == second.Text {
+		t.Errorf("expected no repeat on the same day, got %q twice", first.Text)
+	}
+
+	// Shown tips are forgotten the next day
+	p.Pick(day.Add(20*time.Minute), time.Minute)
+	if len(p.shown) != 1 {
+		t.Errorf("expected only today's tip to be remembered, got %v", p.shown)
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, dir, "mine.md", "## Hydrate\n- Drink a glass of water. (1m)\n")
+
+	tests := []struct {
+		name    string
+		cfg     config.TipsConfig
+		want    int
+		wantErr bool
+	}{
+		{name: "Builtin", cfg: config.TipsConfig{Builtin: true}, want: len(builtin)},
+		{name: "Builtin and file", cfg: config.TipsConfig{Builtin: true, Files: []string{file}}, want: len(builtin) + 1},
+		{name: "Categories", cfg: config.TipsConfig{Builtin: true, Files: []string{file}, Categories: []string{"hydrate"}}, want: 1},
+		{name: "Missing file", cfg: config.TipsConfig{Builtin: true, Files: []string{dir + "/missing.yaml"}}, want: len(builtin), wantErr: true},
+		{name: "Nothing", cfg: config.TipsConfig{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if p.Len() != tt.want {
+				t.Errorf("expected %d tips, got %d", tt.want, p.Len())
+			}
+		})
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	// Built-in tips are complete, and even short breaks get one
+	p, _ := New(config.TipsConfig{Builtin: true}, WithRand(rand.New(rand.NewPCG(1, 2))))
+	for _, tip := range builtin {
+		if tip.Text == "" || tip.Category == "" || tip.Duration <= 0 {
+			t.Errorf("incomplete built-in tip %+v", tip)
+		}
+	}
+	if _, ok := p.Pick(time.Now(), 20*time.Second); !ok {
+		t.Error("expected a tip for a 20 second break")
+	}
+}